@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"azure.com/ecovo/trip-service/cmd/middleware/auth"
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/usage"
 )
 
 // Auth validates a request's authorization header using the given validator
@@ -14,7 +16,9 @@ import (
 // authenticated user's information.
 //
 // The authenticated user's information placed in the request's context and can
-// be accessed by using the auth.FromContext utility function.
+// be accessed by using the auth.FromContext utility function. The calls made
+// to the routing providers during the request are charged to the user it
+// identifies, if any.
 func Auth(validators map[string]auth.Validator, next Handler) Handler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		header := r.Header.Get("Authorization")
@@ -37,12 +41,41 @@ func Auth(validators map[string]auth.Validator, next Handler) Handler {
 		}
 
 		ctx := context.WithValue(r.Context(), auth.UserInfoContextKey, userInfo)
+		if userInfo.UserID != "" {
+			ctx = usage.NewContext(ctx, entity.NewIDFromHex(userInfo.UserID))
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 
 		return nil
 	}
 }
 
+// userIDFromRequest returns the ID of the user on whose behalf the request is
+// made. Users can only act on their own behalf, so when the request comes from
+// a user, the requested ID must be empty or theirs. Other services, which
+// authenticate with basic auth, can act on behalf of any requested user.
+func userIDFromRequest(r *http.Request, requested entity.ID) (entity.ID, error) {
+	userInfo, err := auth.FromContext(r.Context())
+	if err != nil {
+		return entity.NilID, err
+	}
+
+	if userInfo.IsService() {
+		return requested, nil
+	}
+
+	if userInfo.UserID == "" {
+		return entity.NilID, auth.ForbiddenError{Msg: "auth: token does not identify a user"}
+	}
+
+	userID := entity.NewIDFromHex(userInfo.UserID)
+	if !requested.IsZero() && requested != userID {
+		return entity.NilID, auth.ForbiddenError{Msg: fmt.Sprintf("auth: user \"%s\" cannot act on behalf of user \"%s\"", userID, requested)}
+	}
+
+	return userID, nil
+}
+
 func parseHeader(header string) (string, string, error) {
 	headerParts := strings.Split(header, " ")
 	if len(headerParts) < 2 {
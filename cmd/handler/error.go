@@ -6,6 +6,8 @@ import (
 
 	"azure.com/ecovo/trip-service/cmd/middleware/auth"
 	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/route"
+	"azure.com/ecovo/trip-service/pkg/search"
 	"azure.com/ecovo/trip-service/pkg/trip"
 )
 
@@ -27,8 +29,16 @@ func WrapError(err error) *Error {
 		return nil
 	} else if _, ok := err.(auth.UnauthorizedError); ok {
 		return &Error{http.StatusUnauthorized, "unauthorized", err}
+	} else if _, ok := err.(auth.ForbiddenError); ok {
+		return &Error{http.StatusForbidden, "forbidden", err}
 	} else if _, ok := err.(trip.NotFoundError); ok {
 		return &Error{http.StatusNotFound, "trip does not exist", err}
+	} else if _, ok := err.(search.NotFoundError); ok {
+		return &Error{http.StatusNotFound, "search does not exist", err}
+	} else if _, ok := err.(route.QuotaExceededError); ok {
+		return &Error{http.StatusTooManyRequests, "daily quota of route calculations exceeded, please try again tomorrow", err}
+	} else if _, ok := err.(route.UnavailableError); ok {
+		return &Error{http.StatusServiceUnavailable, "routing is unavailable, please try again later", err}
 	} else if _, ok := err.(entity.ValidationError); ok {
 		return &Error{http.StatusBadRequest, err.Error(), err}
 	} else {
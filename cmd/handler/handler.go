@@ -6,7 +6,7 @@ import (
 	"log"
 	"net/http"
 
-	"azure.com/ecovo/trip-service/cmd/middleware/requestid"
+	"azure.com/ecovo/trip-service/pkg/requestid"
 )
 
 // A Handler represents a handler that can return an error.
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/itinerary"
+	"github.com/gorilla/schema"
+)
+
+// GetItineraries handles a request to retrieve itineraries made of multiple
+// trips.
+func GetItineraries(service itinerary.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var decoder = schema.NewDecoder()
+		var f entity.ItineraryFilters
+
+		err := decoder.Decode(&f, r.URL.Query())
+		if err != nil {
+			return err
+		}
+
+		i, err := service.Find(&f)
+		if err != nil {
+			return err
+		}
+
+		for _, it := range i {
+			for _, leg := range it.Legs {
+				hideProposals(r, leg.Trip)
+			}
+		}
+
+		err = json.NewEncoder(w).Encode(i)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// CreateItineraryReservation handles a request to reserve every leg of an
+// itinerary.
+func CreateItineraryReservation(service itinerary.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var res *entity.ItineraryReservation
+		err := json.NewDecoder(r.Body).Decode(&res)
+		if err != nil {
+			return err
+		}
+
+		err = service.Reserve(res)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusCreated)
+
+		return nil
+	}
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// GetMetrics handles a request to retrieve the service's runtime metrics
+// published with the expvar package.
+func GetMetrics() Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		expvar.Handler().ServeHTTP(w, r)
+
+		return nil
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"net/http"
 
-	"azure.com/ecovo/trip-service/cmd/middleware/requestid"
+	"azure.com/ecovo/trip-service/pkg/requestid"
 	"github.com/google/uuid"
 )
 
@@ -26,6 +26,11 @@ func CreateReservation(service reservation.UseCase) Handler {
 
 		w.WriteHeader(http.StatusCreated)
 
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}
 }
@@ -51,3 +56,28 @@ func DeleteReservation(service reservation.UseCase) Handler {
 		return nil
 	}
 }
+
+// GetEmissions handles a request to retrieve the environmental impact of a
+// user's reservations.
+func GetEmissions(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		userID, err := userIDFromRequest(r, entity.NewIDFromHex(r.URL.Query().Get("userId")))
+		if err != nil {
+			return err
+		}
+
+		summary, err := service.Emissions(userID)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(summary)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/search"
+	"github.com/gorilla/mux"
+)
+
+// CreateSearch handles a request to save a search.
+func CreateSearch(service search.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var s *entity.Search
+		err := json.NewDecoder(r.Body).Decode(&s)
+		if err != nil {
+			return err
+		}
+
+		if s != nil {
+			s.UserID, err = userIDFromRequest(r, s.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		s, err = service.Register(s)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusCreated)
+
+		err = json.NewEncoder(w).Encode(s)
+		if err != nil {
+			_ = service.Delete(s.ID)
+
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetSearchByID handles a request to retrieve a saved search by its unique
+// identifier.
+func GetSearchByID(service search.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+		s, err := service.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		_, err = userIDFromRequest(r, s.UserID)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(s)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetSearches handles a request to retrieve the searches saved by a user.
+func GetSearches(service search.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		userID, err := userIDFromRequest(r, entity.NewIDFromHex(r.URL.Query().Get("userId")))
+		if err != nil {
+			return err
+		}
+
+		s, err := service.FindByUserID(userID)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(s)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteSearch handles a request to delete a saved search by its unique
+// identifier.
+func DeleteSearch(service search.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+		s, err := service.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		_, err = userIDFromRequest(r, s.UserID)
+		if err != nil {
+			return err
+		}
+
+		err = service.Delete(id)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		return nil
+	}
+}
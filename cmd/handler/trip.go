@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"azure.com/ecovo/trip-service/cmd/middleware/auth"
 	"azure.com/ecovo/trip-service/pkg/entity"
 	"azure.com/ecovo/trip-service/pkg/trip"
 	"github.com/gorilla/mux"
@@ -21,7 +22,7 @@ func CreateTrip(service trip.UseCase) Handler {
 			return err
 		}
 
-		t, err = service.Register(t)
+		t, err = service.Register(r.Context(), t)
 		if err != nil {
 			return err
 		}
@@ -39,6 +40,32 @@ func CreateTrip(service trip.UseCase) Handler {
 	}
 }
 
+// QuoteTrip handles a request to preview the route, times and price of a trip
+// without creating it.
+func QuoteTrip(service trip.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var t *entity.Trip
+		err := json.NewDecoder(r.Body).Decode(&t)
+		if err != nil {
+			return err
+		}
+
+		t, err = service.Quote(r.Context(), t)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(t)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
 // DeleteTrip handles a request to delete a trip by its unique identifier.
 func DeleteTrip(service trip.UseCase) Handler {
 	return func(w http.ResponseWriter, r *http.Request) error {
@@ -70,6 +97,8 @@ func GetTripByID(tService trip.UseCase) Handler {
 			return err
 		}
 
+		hideProposals(r, t)
+
 		err = json.NewEncoder(w).Encode(t)
 		if err != nil {
 			return err
@@ -99,6 +128,8 @@ func GetTrips(service trip.UseCase) Handler {
 			return err
 		}
 
+		hideProposals(r, t...)
+
 		err = json.NewEncoder(w).Encode(t)
 		if err != nil {
 			return err
@@ -107,3 +138,227 @@ func GetTrips(service trip.UseCase) Handler {
 		return nil
 	}
 }
+
+// GetTripFacets handles a request to count the trips matching a search, broken
+// down by some of their characteristics.
+func GetTripFacets(service trip.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var decoder = schema.NewDecoder()
+		var f entity.FacetFilters
+
+		err := decoder.Decode(&f, r.URL.Query())
+		if err != nil {
+			return err
+		}
+
+		facets, err := service.Facets(&f)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(facets)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetTripDetour handles a request to estimate the detour a trip's driver
+// would make to pick up and drop off a passenger.
+func GetTripDetour(service trip.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		var decoder = schema.NewDecoder()
+		var req entity.DetourRequest
+
+		err := decoder.Decode(&req, r.URL.Query())
+		if err != nil {
+			return err
+		}
+
+		d, err := service.Detour(r.Context(), entity.NewIDFromHex(vars["id"]), &req)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(d)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetTripFareQuote handles a request to compute the fare a passenger would pay
+// to reserve seats on a segment of a trip.
+func GetTripFareQuote(service trip.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		var decoder = schema.NewDecoder()
+		var req entity.FareQuoteRequest
+
+		err := decoder.Decode(&req, r.URL.Query())
+		if err != nil {
+			return err
+		}
+
+		q, err := service.QuoteFare(entity.NewIDFromHex(vars["id"]), &req)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(q)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// CreateProposal handles a request from a passenger to propose a stop for a
+// trip.
+func CreateProposal(service trip.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		var p *entity.Proposal
+		err := json.NewDecoder(r.Body).Decode(&p)
+		if err != nil {
+			return err
+		}
+
+		if p != nil {
+			p.UserID, err = userIDFromRequest(r, p.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		p, err = service.Propose(entity.NewIDFromHex(vars["id"]), p)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusCreated)
+
+		err = json.NewEncoder(w).Encode(p)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// AcceptProposal handles a request from a driver to accept the stop proposed
+// by a passenger, which is added to the trip.
+func AcceptProposal(service trip.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+		err := authorizeDriver(r, service, id)
+		if err != nil {
+			return err
+		}
+
+		t, err := service.AcceptProposal(r.Context(), id, entity.NewIDFromHex(vars["proposalId"]))
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(t)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// RejectProposal handles a request from a driver to reject the stop proposed
+// by a passenger.
+func RejectProposal(service trip.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+		err := authorizeDriver(r, service, id)
+		if err != nil {
+			return err
+		}
+
+		p, err := service.RejectProposal(id, entity.NewIDFromHex(vars["proposalId"]))
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(w).Encode(p)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// authorizeDriver checks that the request is made by the driver of the trip
+// with the given ID, or by another service.
+func authorizeDriver(r *http.Request, service trip.UseCase, ID entity.ID) error {
+	t, err := service.FindByID(ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = userIDFromRequest(r, t.DriverID)
+
+	return err
+}
+
+// hideProposals removes the proposals that the user making the request is not
+// allowed to see from the trips. A trip's driver sees all of its proposals,
+// while the other users only see the ones they made. Other services see every
+// proposal.
+func hideProposals(r *http.Request, trips ...*entity.Trip) {
+	userInfo, err := auth.FromContext(r.Context())
+	if err == nil && userInfo.IsService() {
+		return
+	}
+
+	var userID entity.ID
+	if err == nil {
+		userID = entity.NewIDFromHex(userInfo.UserID)
+	}
+
+	for _, t := range trips {
+		if !userID.IsZero() && userID == t.DriverID {
+			continue
+		}
+
+		var proposals []*entity.Proposal
+		for _, p := range t.Proposals {
+			if !userID.IsZero() && userID == p.UserID {
+				proposals = append(proposals, p)
+			}
+		}
+		t.Proposals = proposals
+	}
+}
@@ -1,19 +1,34 @@
 package main
 
 import (
+	"encoding/json"
+	"expvar"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	// The time zone database is embedded since the image it runs in does not
+	// have one.
+	_ "time/tzdata"
+
 	"azure.com/ecovo/trip-service/cmd/handler"
 	"azure.com/ecovo/trip-service/cmd/middleware/auth"
 	"azure.com/ecovo/trip-service/pkg/db"
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/geocode"
+	"azure.com/ecovo/trip-service/pkg/itinerary"
+	"azure.com/ecovo/trip-service/pkg/pricing"
 	"azure.com/ecovo/trip-service/pkg/pubsub"
 	"azure.com/ecovo/trip-service/pkg/pubsub/subscription"
 	"azure.com/ecovo/trip-service/pkg/reservation"
 	"azure.com/ecovo/trip-service/pkg/route"
+	"azure.com/ecovo/trip-service/pkg/search"
 	"azure.com/ecovo/trip-service/pkg/trip"
+	"azure.com/ecovo/trip-service/pkg/usage"
 	"github.com/ably/ably-go/ably"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -29,6 +44,7 @@ func main() {
 	authConfig := auth.Config{
 		Domain:               os.Getenv("AUTH_DOMAIN"),
 		BasicAuthCredentials: os.Getenv("AUTH_CREDENTIALS"),
+		UserIDClaim:          os.Getenv("AUTH_USER_ID_CLAIM"),
 	}
 	authBasicValidator, err := auth.NewBasicAuthValidator(&authConfig)
 	if err != nil {
@@ -69,36 +85,230 @@ func main() {
 	}
 	pubSubService := pubsub.NewService(ablyPubSubRepository)
 
-	mapsClient, err := maps.NewClient(maps.WithAPIKey(os.Getenv("GOOGLE_MAPS_API_KEY")))
+	usageRepository, err := usage.NewMongoRepository(db.Usage)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	routeRepository, err := route.NewGoogleMapsRepository(mapsClient)
+	routeDailyQuotas := make(map[string]int)
+	if quotas := os.Getenv("ROUTE_DAILY_QUOTAS"); quotas != "" {
+		err = json.Unmarshal([]byte(quotas), &routeDailyQuotas)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	routeProviderNames := strings.Split(os.Getenv("ROUTE_PROVIDER"), ",")
+	routeProviders := make([]*route.Provider, len(routeProviderNames))
+	for i, name := range routeProviderNames {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = "google"
+		}
+
+		repo, err := newRouteRepository(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		repo, err = route.NewTrackingRepository(name, repo, usageRepository, routeDailyQuotas[name])
+		if err != nil {
+			log.Fatal(err)
+		}
+		routeProviders[i] = &route.Provider{Name: name, Repository: repo}
+	}
+
+	routeProviderTimeout, err := time.ParseDuration(os.Getenv("ROUTE_PROVIDER_TIMEOUT") + "s")
+	if err != nil || routeProviderTimeout <= 0 {
+		routeProviderTimeout = route.DefaultFailoverTimeout
+	}
+
+	routeFailover, err := route.NewFailoverRepository(routeProviders, &route.FailoverConfig{
+		Timeout: routeProviderTimeout,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
-	routeUseCase := route.NewService(routeRepository)
+	expvar.Publish("routeProviders", expvar.Func(func() interface{} {
+		return routeFailover.Stats()
+	}))
+	var routeRepository route.Repository = routeFailover
+
+	routeUserDailyQuota := int(floatFromEnv("ROUTE_USER_DAILY_QUOTA", 0))
+	if routeUserDailyQuota > 0 {
+		routeRepository, err = route.NewQuotaRepository(routeRepository, usageRepository, routeUserDailyQuota)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	routeCacheSize := int(floatFromEnv("ROUTE_CACHE_SIZE", route.DefaultCacheSize))
+	if routeCacheSize > 0 {
+		routeCacheTTL, err := time.ParseDuration(os.Getenv("ROUTE_CACHE_TTL") + "s")
+		if err != nil {
+			routeCacheTTL = route.DefaultCacheTTL
+		}
+
+		routeCache, err := route.NewCachingRepository(routeRepository, &route.CacheConfig{
+			TTL:        routeCacheTTL,
+			Size:       routeCacheSize,
+			Precision:  route.DefaultCachePrecision,
+			BucketSize: route.DefaultCacheBucketSize,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		expvar.Publish("routeCache", expvar.Func(func() interface{} {
+			return routeCache.Stats()
+		}))
+		routeRepository = routeCache
+	}
+	routeDwellTime, err := time.ParseDuration(os.Getenv("ROUTE_DWELL_TIME") + "s")
+	if err != nil || routeDwellTime < 0 {
+		routeDwellTime = route.DefaultDwellTime
+	}
+	routeUseCase := route.NewService(routeRepository, routeDwellTime)
+
+	var geocodeRepository geocode.Repository
+	switch os.Getenv("GEOCODE_PROVIDER") {
+	case "fixture":
+		geocodeRepository, err = geocode.NewFixtureRepositoryFromFile(os.Getenv("GEOCODE_FIXTURES"))
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
+		if apiKey == "" {
+			// Without an API key, only stops given as coordinates can be
+			// used, so that the service still runs offline
+			log.Println("GOOGLE_MAPS_API_KEY is not set, stops given as addresses or place IDs will not be resolved")
+
+			geocodeRepository, err = geocode.NewFixtureRepository(map[string][]*entity.Point{})
+			if err != nil {
+				log.Fatal(err)
+			}
+			break
+		}
+
+		mapsClient, err := maps.NewClient(maps.WithAPIKey(apiKey))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		geocodeRepository, err = geocode.NewGoogleMapsRepository(mapsClient)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	geocodeUseCase := geocode.NewService(geocodeRepository)
+
+	pricingRate := floatFromEnv("PRICING_RATE", pricing.PricePerKilometer)
+	var pricingStrategy pricing.Strategy
+	switch os.Getenv("PRICING_STRATEGY") {
+	case "region":
+		var regions []*pricing.Region
+		err = json.Unmarshal([]byte(os.Getenv("PRICING_REGIONS")), &regions)
+		if err != nil {
+			log.Fatalf("failed to parse PRICING_REGIONS (%s)", err)
+		}
+
+		pricingStrategy, err = pricing.NewRegionStrategy(regions, pricingRate)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "vehicleClass":
+		var rates map[string]float64
+		err = json.Unmarshal([]byte(os.Getenv("PRICING_VEHICLE_CLASS_RATES")), &rates)
+		if err != nil {
+			log.Fatalf("failed to parse PRICING_VEHICLE_CLASS_RATES (%s)", err)
+		}
+
+		pricingStrategy, err = pricing.NewVehicleClassStrategy(rates, pricingRate)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "fuelConsumption":
+		pricingStrategy, err = pricing.NewFuelConsumptionStrategy(
+			floatFromEnv("PRICING_FUEL_PRICE", pricing.DefaultFuelPrice),
+			floatFromEnv("PRICING_ELECTRICITY_PRICE", pricing.DefaultElectricityPrice),
+			floatFromEnv("PRICING_DEFAULT_CONSUMPTION", pricing.DefaultConsumption),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		pricingStrategy, err = pricing.NewPerKilometerStrategy(pricingRate)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	pricingUseCase, err := pricing.NewService(pricingStrategy, &pricing.Config{
+		MinimumFare:       floatFromEnv("PRICING_MINIMUM_FARE", 0),
+		RoundingIncrement: floatFromEnv("PRICING_ROUNDING_INCREMENT", 0),
+		RoundingMode:      os.Getenv("PRICING_ROUNDING_MODE"),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	searchRepository, err := search.NewMongoRepository(db.Searches)
+	if err != nil {
+		log.Fatal(err)
+	}
+	searchUseCase := search.NewService(searchRepository, pubSubService)
 
 	tripRepository, err := trip.NewMongoRepository(db.Trips)
 	if err != nil {
 		log.Fatal(err)
 	}
-	tripUseCase := trip.NewService(tripRepository, pubSubService, routeUseCase)
+	rankingWeights := trip.RankingWeights{
+		WalkingDistance: floatFromEnv("RANKING_WALKING_DISTANCE_WEIGHT", trip.DefaultRankingWeights.WalkingDistance),
+		TimeDifference:  floatFromEnv("RANKING_TIME_DIFFERENCE_WEIGHT", trip.DefaultRankingWeights.TimeDifference),
+		PricePerSeat:    floatFromEnv("RANKING_PRICE_PER_SEAT_WEIGHT", trip.DefaultRankingWeights.PricePerSeat),
+		RemainingSeats:  floatFromEnv("RANKING_REMAINING_SEATS_WEIGHT", trip.DefaultRankingWeights.RemainingSeats),
+	}
+	ranker, err := trip.NewRanker(&rankingWeights)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	reservationUseCase := reservation.NewService(tripUseCase)
+	reservationRepository, err := reservation.NewMongoRepository(db.Reservations)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tripUseCase := trip.NewService(tripRepository, pubSubService, reservationRepository, geocodeUseCase, routeUseCase, pricingUseCase, searchUseCase, ranker)
+
+	reservationUseCase := reservation.NewService(reservationRepository, tripUseCase)
+
+	itineraryUseCase := itinerary.NewService(tripUseCase, reservationUseCase)
 
 	r := mux.NewRouter()
 
 	// Trips
 	r.Handle("/trips", handler.RequestID(handler.Auth(authValidators, handler.GetTrips(tripUseCase)))).
 		Methods("GET")
+	r.Handle("/trips/facets", handler.RequestID(handler.Auth(authValidators, handler.GetTripFacets(tripUseCase)))).
+		Methods("GET")
+	r.Handle("/trips/{id}/detour", handler.RequestID(handler.Auth(authValidators, handler.GetTripDetour(tripUseCase)))).
+		Methods("GET")
+	r.Handle("/trips/{id}/quote", handler.RequestID(handler.Auth(authValidators, handler.GetTripFareQuote(tripUseCase)))).
+		Methods("GET")
 	r.Handle("/trips/{id}", handler.RequestID(handler.Auth(authValidators, handler.GetTripByID(tripUseCase)))).
 		Methods("GET").
 		Headers("Content-Type", "application/json")
+	r.Handle("/trips/quote", handler.RequestID(handler.Auth(authValidators, handler.QuoteTrip(tripUseCase)))).
+		Methods("POST").
+		HeadersRegexp("Content-Type", "application/(json|json; charset=utf8)")
 	r.Handle("/trips", handler.RequestID(handler.Auth(authValidators, handler.CreateTrip(tripUseCase)))).
 		Methods("POST").
 		HeadersRegexp("Content-Type", "application/(json|json; charset=utf8)")
+	r.Handle("/trips/{id}/proposals", handler.RequestID(handler.Auth(authValidators, handler.CreateProposal(tripUseCase)))).
+		Methods("POST").
+		HeadersRegexp("Content-Type", "application/(json|json; charset=utf8)")
+	r.Handle("/trips/{id}/proposals/{proposalId}/accept", handler.RequestID(handler.Auth(authValidators, handler.AcceptProposal(tripUseCase)))).
+		Methods("POST")
+	r.Handle("/trips/{id}/proposals/{proposalId}/reject", handler.RequestID(handler.Auth(authValidators, handler.RejectProposal(tripUseCase)))).
+		Methods("POST")
 	r.Handle("/trips/{id}/reservation", handler.RequestID(handler.Auth(authValidators, handler.CreateReservation(reservationUseCase)))).
 		Methods("POST").
 		HeadersRegexp("Content-Type", "application/(json|json; charset=utf8)")
@@ -108,5 +318,88 @@ func main() {
 	r.Handle("/trips/{id}", handler.RequestID(handler.Auth(authValidators, handler.DeleteTrip(tripUseCase)))).
 		Methods("DELETE").
 		HeadersRegexp("Content-Type", "application/json")
+
+	// Itineraries
+	r.Handle("/itineraries", handler.RequestID(handler.Auth(authValidators, handler.GetItineraries(itineraryUseCase)))).
+		Methods("GET")
+	r.Handle("/itineraries/reservation", handler.RequestID(handler.Auth(authValidators, handler.CreateItineraryReservation(itineraryUseCase)))).
+		Methods("POST").
+		HeadersRegexp("Content-Type", "application/(json|json; charset=utf8)")
+
+	// Searches
+	r.Handle("/searches", handler.RequestID(handler.Auth(authValidators, handler.GetSearches(searchUseCase)))).
+		Methods("GET")
+	r.Handle("/searches/{id}", handler.RequestID(handler.Auth(authValidators, handler.GetSearchByID(searchUseCase)))).
+		Methods("GET").
+		Headers("Content-Type", "application/json")
+	r.Handle("/searches", handler.RequestID(handler.Auth(authValidators, handler.CreateSearch(searchUseCase)))).
+		Methods("POST").
+		HeadersRegexp("Content-Type", "application/(json|json; charset=utf8)")
+	r.Handle("/searches/{id}", handler.RequestID(handler.Auth(authValidators, handler.DeleteSearch(searchUseCase)))).
+		Methods("DELETE").
+		HeadersRegexp("Content-Type", "application/json")
+	r.Handle("/emissions", handler.RequestID(handler.Auth(authValidators, handler.GetEmissions(reservationUseCase)))).
+		Methods("GET")
+
+	// Metrics are only available to other services, which authenticate with
+	// basic auth
+	metricsValidators := map[string]auth.Validator{
+		"basic": authBasicValidator,
+	}
+	r.Handle("/debug/vars", handler.RequestID(handler.Auth(metricsValidators, handler.GetMetrics()))).
+		Methods("GET")
 	log.Fatal(http.ListenAndServe(":"+port, handlers.LoggingHandler(os.Stdout, r)))
 }
+
+// floatFromEnv parses the environment variable with the given name as a float,
+// or returns the default value if it is not defined or is not a number.
+func floatFromEnv(name string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// newRouteRepository creates the repository of the routing provider with the
+// given name, configured from the environment.
+func newRouteRepository(provider string) (route.Repository, error) {
+	switch provider {
+	case "greatcircle":
+		return route.NewGreatCircleRepository(&route.GreatCircleConfig{
+			AverageSpeed:       floatFromEnv("ROUTE_AVERAGE_SPEED", route.DefaultAverageSpeed),
+			RoadDistanceFactor: floatFromEnv("ROUTE_ROAD_DISTANCE_FACTOR", route.DefaultRoadDistanceFactor),
+		})
+	case "osrm":
+		osrmTimeout, err := time.ParseDuration(os.Getenv("OSRM_TIMEOUT") + "s")
+		if err != nil {
+			osrmTimeout = route.DefaultOSRMTimeout
+		}
+
+		return route.NewOSRMRepository(&route.OSRMConfig{
+			URL:     os.Getenv("OSRM_URL"),
+			Profile: os.Getenv("OSRM_PROFILE"),
+		}, &http.Client{Timeout: osrmTimeout})
+	case "google":
+		googleMapsTimeout, err := time.ParseDuration(os.Getenv("GOOGLE_MAPS_TIMEOUT") + "s")
+		if err != nil {
+			googleMapsTimeout = route.DefaultGoogleMapsTimeout
+		}
+		googleMapsBreakerCooldown, err := time.ParseDuration(os.Getenv("GOOGLE_MAPS_BREAKER_COOLDOWN") + "s")
+		if err != nil {
+			googleMapsBreakerCooldown = route.DefaultGoogleMapsBreakerCooldown
+		}
+
+		return route.NewGoogleMapsRepository(&route.GoogleMapsConfig{
+			APIKey:           os.Getenv("GOOGLE_MAPS_API_KEY"),
+			Timeout:          googleMapsTimeout,
+			MaxRetries:       int(floatFromEnv("GOOGLE_MAPS_MAX_RETRIES", route.DefaultGoogleMapsMaxRetries)),
+			RetryBackoff:     route.DefaultGoogleMapsRetryBackoff,
+			BreakerThreshold: int(floatFromEnv("GOOGLE_MAPS_BREAKER_THRESHOLD", route.DefaultGoogleMapsBreakerThreshold)),
+			BreakerCooldown:  googleMapsBreakerCooldown,
+		})
+	default:
+		return nil, fmt.Errorf("unknown routing provider \"%s\"", provider)
+	}
+}
@@ -16,6 +16,9 @@ type UserInfo struct {
 	LastName  string `json:"family_name"`
 	Picture   string `json:"picture"`
 	Email     string `json:"email"`
+	// UserID represents the user's unique identifier in the application,
+	// read from the claim named in the validator's configuration.
+	UserID string `json:"-"`
 }
 
 // Config contains the information required to configure a validator to make
@@ -26,6 +29,10 @@ type Config struct {
 	// BasicAuthCredentials represents the base64 encoded username and password
 	// used to authenticate another service with basic auth.
 	BasicAuthCredentials string
+	// UserIDClaim represents the name of the user info claim that contains
+	// the user's unique identifier in the application. When empty, users
+	// authenticated with a token have no user ID.
+	UserIDClaim string
 }
 
 // Validate looks at the configuration's contents to ensure it has all the
@@ -88,11 +95,22 @@ func (validator *TokenValidator) Validate(credentials string) (*UserInfo, error)
 		return nil, UnauthorizedError{fmt.Sprintf("auth: failed to validate token")}
 	}
 
-	var userInfo UserInfo
-	err = json.NewDecoder(resp.Body).Decode(&userInfo)
+	var claims map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&claims)
 	if err != nil {
 		return nil, UnauthorizedError{fmt.Sprintf("auth: failed to decode user info (%s)", err)}
 	}
+
+	userInfo := UserInfo{
+		SubID:     claimString(claims, "sub"),
+		FirstName: claimString(claims, "given_name"),
+		LastName:  claimString(claims, "family_name"),
+		Picture:   claimString(claims, "picture"),
+		Email:     claimString(claims, "email"),
+	}
+	if validator.conf.UserIDClaim != "" {
+		userInfo.UserID = claimString(claims, validator.conf.UserIDClaim)
+	}
 	return &userInfo, nil
 }
 
@@ -128,6 +146,17 @@ func (validator *BasicAuthValidator) Validate(credentials string) (*UserInfo, er
 	return nil, UnauthorizedError{"auth: failed to decode user info"}
 }
 
+func claimString(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// IsService reports whether the user information belongs to another service
+// authenticated with basic auth rather than to a user.
+func (u *UserInfo) IsService() bool {
+	return u.SubID == ""
+}
+
 type contextKey string
 
 func (c contextKey) String() string {
@@ -9,3 +9,13 @@ type UnauthorizedError struct {
 func (e UnauthorizedError) Error() string {
 	return e.Msg
 }
+
+// A ForbiddenError is an error that occurs when the authenticated user is not
+// allowed to access a resource.
+type ForbiddenError struct {
+	Msg string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Msg
+}
@@ -61,12 +61,18 @@ func (conf *Config) validate() error {
 // DB represents a database. It contains a client used to connect to a database
 // server and the database's collections.
 type DB struct {
-	client *mongo.Client
-	Trips  *mongo.Collection
+	client       *mongo.Client
+	Trips        *mongo.Collection
+	Searches     *mongo.Collection
+	Reservations *mongo.Collection
+	Usage        *mongo.Collection
 }
 
 const (
-	tripCollectionName = "trips"
+	tripCollectionName        = "trips"
+	searchCollectionName      = "searches"
+	reservationCollectionName = "reservations"
+	usageCollectionName       = "usage"
 )
 
 // New creates a database by establishing a connection to the database server
@@ -110,5 +116,20 @@ func New(conf *Config) (*DB, error) {
 		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", tripCollectionName)
 	}
 
-	return &DB{client, trips}, nil
+	searches := db.Collection(searchCollectionName)
+	if searches == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", searchCollectionName)
+	}
+
+	reservations := db.Collection(reservationCollectionName)
+	if reservations == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", reservationCollectionName)
+	}
+
+	usage := db.Collection(usageCollectionName)
+	if usage == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", usageCollectionName)
+	}
+
+	return &DB{client, trips, searches, reservations, usage}, nil
 }
@@ -0,0 +1,196 @@
+package entity
+
+import (
+	"math"
+)
+
+// A path is a line made of points, such as the route driven during a trip.
+type path struct {
+	points []*Point
+	along  []float64
+}
+
+// A projection is the point of a path that is the closest to another point.
+type projection struct {
+	point    *Point
+	segment  int
+	distance float64
+	along    float64
+}
+
+func newPath(points []*Point) *path {
+	along := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		along[i] = along[i-1] + points[i-1].DistanceTo(points[i])
+	}
+
+	return &path{points, along}
+}
+
+// projections returns the projection of the point on each of the path's
+// segments.
+func (pa *path) projections(p *Point) []*projection {
+	projections := make([]*projection, len(pa.points)-1)
+	for i := range projections {
+		projections[i] = pa.projectOnSegment(p, i)
+	}
+
+	return projections
+}
+
+// project returns the projection of the point on the path, only considering
+// the segments starting at the given segment.
+func (pa *path) project(p *Point, fromSegment int) *projection {
+	var nearest *projection
+	for i := fromSegment; i < len(pa.points)-1; i++ {
+		proj := pa.projectOnSegment(p, i)
+		if nearest == nil || proj.distance < nearest.distance {
+			nearest = proj
+		}
+	}
+
+	return nearest
+}
+
+// projectOnSegment returns the projection of the point on the path's segment
+// that starts at the given index. It uses an equirectangular approximation,
+// which is precise enough at the scale of a road segment.
+func (pa *path) projectOnSegment(p *Point, segment int) *projection {
+	a := pa.points[segment]
+	b := pa.points[segment+1]
+
+	kx := math.Cos(p.Latitude*math.Pi/180) * EarthRadius * math.Pi / 180
+	ky := EarthRadius * math.Pi / 180
+
+	ax := (a.Longitude - p.Longitude) * kx
+	ay := (a.Latitude - p.Latitude) * ky
+	dx := (b.Longitude-p.Longitude)*kx - ax
+	dy := (b.Latitude-p.Latitude)*ky - ay
+
+	t := 0.0
+	if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSquared))
+	}
+
+	return &projection{
+		point: &Point{
+			Latitude:  a.Latitude + t*(b.Latitude-a.Latitude),
+			Longitude: a.Longitude + t*(b.Longitude-a.Longitude),
+		},
+		segment:  segment,
+		distance: math.Hypot(ax+t*dx, ay+t*dy),
+		along:    pa.along[segment] + t*(pa.along[segment+1]-pa.along[segment]),
+	}
+}
+
+// routePoints returns the points of the route driven during the trip. If the
+// trip's route geometry is unknown, the route is approximated by straight
+// lines between its stops.
+func routePoints(t *Trip) []*Point {
+	if t.Polyline != "" {
+		points, err := DecodePolyline(t.Polyline)
+		if err == nil && len(points) >= 2 {
+			return points
+		}
+	}
+
+	points := make([]*Point, 0, len(t.Stops))
+	for _, s := range t.Stops {
+		if s.Point != nil {
+			points = append(points, s.Point)
+		}
+	}
+
+	return points
+}
+
+// matchCorridor finds where a passenger searching with the filters would be
+// picked up and dropped off along the trip's route. The passenger's source and
+// destination must be within the radius threshold of the route, in the order
+// it is driven. When the filters only specify one of them, the passenger is
+// picked up at the start of the route or dropped off at its end, such as for
+// the legs of an itinerary.
+//
+// The match's pickup and drop-off stops are the stops surrounding the part of
+// the route the passenger will be in the car.
+func (f *Filters) matchCorridor(t *Trip, seats int) *Match {
+	points := routePoints(t)
+	if len(points) < 2 {
+		return nil
+	}
+
+	source := f.Source()
+	destination := f.Destination()
+	if source == nil && destination == nil {
+		return nil
+	} else if source == nil {
+		source = points[0]
+	} else if destination == nil {
+		destination = points[len(points)-1]
+	}
+
+	route := newPath(points)
+	radius := f.Radius()
+
+	sources := route.projections(source)
+	destinations := route.projections(destination)
+
+	var pickup, dropOff, nearestSource *projection
+	for i, d := range destinations {
+		s := sources[i]
+		if d.distance <= radius {
+			candidate := nearestSource
+			if s.distance <= radius && s.along < d.along && (candidate == nil || s.distance < candidate.distance) {
+				candidate = s
+			}
+
+			if candidate != nil && (pickup == nil || candidate.distance+d.distance < pickup.distance+dropOff.distance) {
+				pickup, dropOff = candidate, d
+			}
+		}
+
+		if s.distance <= radius && (nearestSource == nil || s.distance < nearestSource.distance) {
+			nearestSource = s
+		}
+	}
+
+	if pickup == nil {
+		return nil
+	}
+
+	pickupStop, dropOffStop := 0, len(t.Stops)-1
+	segment := 0
+	for i, s := range t.Stops {
+		if s.Point == nil {
+			continue
+		}
+
+		proj := route.project(s.Point, segment)
+		segment = proj.segment
+
+		if i < len(t.Stops)-1 && proj.along <= pickup.along {
+			pickupStop = i
+		}
+
+		if i > pickupStop && proj.along >= dropOff.along && dropOffStop == len(t.Stops)-1 {
+			dropOffStop = i
+		}
+	}
+
+	if t.AvailableSeats(pickupStop, dropOffStop) < seats {
+		return nil
+	}
+
+	if !f.acceptsTimes(t.Stops[pickupStop], t.Stops[dropOffStop]) {
+		return nil
+	}
+
+	return &Match{
+		PickupID:        t.Stops[pickupStop].ID,
+		DropOffID:       t.Stops[dropOffStop].ID,
+		PickupDistance:  pickup.distance,
+		DropOffDistance: dropOff.distance,
+		PickupPoint:     pickup.point,
+		DropOffPoint:    dropOff.point,
+	}
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newTestCorridorTrip creates a trip driven northwards along a meridian,
+// through stops 0.1° (about 11 km) apart, with the given seats left on the
+// leg from each stop.
+func newTestCorridorTrip(seats ...int) *Trip {
+	stops := make([]*Stop, len(seats))
+	for i := range stops {
+		stops[i] = &Stop{
+			ID:    ID(fmt.Sprintf("stop%d", i)),
+			Point: &Point{Latitude: 45 + float64(i)/10, Longitude: -73.5},
+			Seats: seats[i],
+		}
+	}
+
+	return &Trip{Seats: 3, Stops: stops}
+}
+
+func newTestCorridorFilters(source, destination *float64) *Filters {
+	longitude := -73.5
+	f := &Filters{Mode: SearchModeCorridor}
+	if source != nil {
+		f.SourceLatitude, f.SourceLongitude = source, &longitude
+	}
+	if destination != nil {
+		f.DestinationLatitude, f.DestinationLongitude = destination, &longitude
+	}
+
+	return f
+}
+
+func latitude(l float64) *float64 {
+	return &l
+}
+
+func TestFiltersMatchCorridor(t *testing.T) {
+	tests := []struct {
+		name        string
+		seats       []int
+		source      *float64
+		destination *float64
+		pickupID    ID
+		dropOffID   ID
+	}{
+		{"along the route", []int{3, 3, 3, 3}, latitude(45.05), latitude(45.25), "stop0", "stop3"},
+		{"between intermediate stops", []int{3, 3, 3, 3}, latitude(45.12), latitude(45.18), "stop1", "stop2"},
+		{"same segment", []int{3, 3, 3, 3}, latitude(45.02), latitude(45.08), "stop0", "stop1"},
+		{"reversed direction", []int{3, 3, 3, 3}, latitude(45.25), latitude(45.05), "", ""},
+		{"reversed direction on the same segment", []int{3, 3, 3, 3}, latitude(45.08), latitude(45.02), "", ""},
+		{"source off the route", []int{3, 3, 3, 3}, latitude(44.5), latitude(45.25), "", ""},
+		{"only a source", []int{3, 3, 3, 3}, latitude(45.12), nil, "stop1", "stop3"},
+		{"only a destination", []int{3, 3, 3, 3}, nil, latitude(45.18), "stop0", "stop2"},
+		{"no seats left on the way", []int{3, 0, 3, 3}, latitude(45.05), latitude(45.25), "", ""},
+		{"no seats left elsewhere", []int{0, 3, 3, 3}, latitude(45.12), latitude(45.18), "stop1", "stop2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestCorridorFilters(tt.source, tt.destination).Match(newTestCorridorTrip(tt.seats...))
+
+			if tt.pickupID == "" {
+				if m != nil {
+					t.Fatalf("Match() = %+v, want nil", m)
+				}
+				return
+			}
+
+			if m == nil {
+				t.Fatal("Match() = nil, want a match")
+			}
+
+			if m.PickupID != tt.pickupID || m.DropOffID != tt.dropOffID {
+				t.Errorf("Match() stops = (%s, %s), want (%s, %s)", m.PickupID, m.DropOffID, tt.pickupID, tt.dropOffID)
+			}
+		})
+	}
+}
+
+func TestFiltersMatchCorridorProjection(t *testing.T) {
+	source, destination := 45.02, 45.08
+	m := newTestCorridorFilters(&source, &destination).Match(newTestCorridorTrip(3, 3, 3, 3))
+	if m == nil {
+		t.Fatal("Match() = nil, want a match")
+	}
+
+	if m.PickupDistance > 1 || m.DropOffDistance > 1 {
+		t.Errorf("Match() distances = (%f, %f), want points on the route", m.PickupDistance, m.DropOffDistance)
+	}
+
+	if m.PickupPoint.Latitude >= m.DropOffPoint.Latitude {
+		t.Errorf("Match() points = (%v, %v), want the pickup before the drop-off", m.PickupPoint, m.DropOffPoint)
+	}
+}
@@ -0,0 +1,69 @@
+package entity
+
+// Costs contains the costs, other than driving, a driver declares for a trip,
+// which are shared with the passengers. They are in the same currency as the
+// price.
+type Costs struct {
+	Tolls   float64 `json:"tolls"`
+	Ferries float64 `json:"ferries"`
+	Parking float64 `json:"parking"`
+}
+
+// A PriceBreakdown itemizes a price between the part computed from the
+// distance driven and the costs declared by the driver.
+type PriceBreakdown struct {
+	Distance float64 `json:"distance"`
+	Tolls    float64 `json:"tolls"`
+	Ferries  float64 `json:"ferries"`
+	Parking  float64 `json:"parking"`
+	Total    float64 `json:"total"`
+}
+
+// Validate validates that the costs are not negative.
+func (c *Costs) Validate() error {
+	if c.Tolls < 0 {
+		return ValidationError{"tolls must be greater than 0"}
+	}
+
+	if c.Ferries < 0 {
+		return ValidationError{"ferries must be greater than 0"}
+	}
+
+	if c.Parking < 0 {
+		return ValidationError{"parking must be greater than 0"}
+	}
+
+	return nil
+}
+
+// SetPrice sets the trip's total price to the given price for the distance
+// driven plus the costs declared by the driver, and itemizes it.
+func (t *Trip) SetPrice(distancePrice float64) {
+	b := &PriceBreakdown{Distance: distancePrice}
+	if t.Costs != nil {
+		b.Tolls = t.Costs.Tolls
+		b.Ferries = t.Costs.Ferries
+		b.Parking = t.Costs.Parking
+	}
+	b.Total = b.Distance + b.Tolls + b.Ferries + b.Parking
+
+	t.PriceBreakdown = b
+	t.TotalTripPrice = b.Total
+}
+
+// split returns the breakdown of the share of the price paid for one of the
+// given number of seats.
+func (b *PriceBreakdown) split(seats int) *PriceBreakdown {
+	if seats <= 1 {
+		split := *b
+		return &split
+	}
+
+	return &PriceBreakdown{
+		Distance: b.Distance / float64(seats),
+		Tolls:    b.Tolls / float64(seats),
+		Ferries:  b.Ferries / float64(seats),
+		Parking:  b.Parking / float64(seats),
+		Total:    b.Total / float64(seats),
+	}
+}
@@ -0,0 +1,180 @@
+package entity
+
+import (
+	"sort"
+)
+
+// DetourRequest contains the points where a passenger would like to be picked
+// up and dropped off, off the trip's route.
+type DetourRequest struct {
+	SourceLatitude       *float64 `json:"sourceLatitude,omitempty" schema:"sourceLatitude,ommitempty"`
+	SourceLongitude      *float64 `json:"sourceLongitude,omitempty" schema:"sourceLongitude,ommitempty"`
+	DestinationLatitude  *float64 `json:"destinationLatitude,omitempty" schema:"destinationLatitude,ommitempty"`
+	DestinationLongitude *float64 `json:"destinationLongitude,omitempty" schema:"destinationLongitude,ommitempty"`
+}
+
+// A Detour contains the extra distance and time it takes a driver to pick up
+// and drop off a passenger at the best positions among the trip's stops.
+type Detour struct {
+	// PickupIndex and DropOffIndex are the indices the pickup and drop-off
+	// stops would have among the trip's stops once inserted.
+	PickupIndex  int `json:"pickupIndex"`
+	DropOffIndex int `json:"dropOffIndex"`
+
+	// Distance and Duration are the extra distance and time, in meters and
+	// seconds.
+	Distance int `json:"distance"`
+	Duration int `json:"duration"`
+
+	MaxDistance int  `json:"maxDistance"`
+	MaxDuration int  `json:"maxDuration"`
+	Acceptable  bool `json:"acceptable"`
+}
+
+const (
+	// DefaultMaxDetourDistance represents the maximum extra distance (in
+	// meters) a driver accepts to drive for a passenger when none is
+	// specified.
+	DefaultMaxDetourDistance = 5000
+
+	// DefaultMaxDetourDuration represents the maximum extra time (in seconds)
+	// a driver accepts to drive for a passenger when none is specified.
+	DefaultMaxDetourDuration = 600
+)
+
+// Validate validates that the detour request's points are filled out
+// correctly.
+func (r *DetourRequest) Validate() error {
+	source := r.Source()
+	destination := r.Destination()
+	if source == nil || destination == nil {
+		return ValidationError{"detour requires a source and a destination"}
+	}
+
+	err := source.Validate()
+	if err != nil {
+		return err
+	}
+
+	return destination.Validate()
+}
+
+// Source returns the point where the passenger wants to be picked up, or nil
+// if the request does not specify one.
+func (r *DetourRequest) Source() *Point {
+	if r.SourceLatitude == nil || r.SourceLongitude == nil {
+		return nil
+	}
+
+	return &Point{Latitude: *r.SourceLatitude, Longitude: *r.SourceLongitude, Name: "pickup"}
+}
+
+// Destination returns the point where the passenger wants to be dropped off,
+// or nil if the request does not specify one.
+func (r *DetourRequest) Destination() *Point {
+	if r.DestinationLatitude == nil || r.DestinationLongitude == nil {
+		return nil
+	}
+
+	return &Point{Latitude: *r.DestinationLatitude, Longitude: *r.DestinationLongitude, Name: "drop-off"}
+}
+
+// MaxDetour returns the maximum extra distance (in meters) and time (in
+// seconds) the driver accepts to drive for a passenger, or the defaults if
+// the trip does not specify them.
+func (t *Trip) MaxDetour() (int, int) {
+	distance := t.MaxDetourDistance
+	if distance == 0 {
+		distance = DefaultMaxDetourDistance
+	}
+
+	duration := t.MaxDetourDuration
+	if duration == 0 {
+		duration = DefaultMaxDetourDuration
+	}
+
+	return distance, duration
+}
+
+// An Insertion is a position where a pickup and a drop-off stop can be
+// inserted among a trip's stops. The indices are the ones the stops have once
+// inserted. The drop-off index is 0 when only a pickup stop is inserted.
+type Insertion struct {
+	PickupIndex  int
+	DropOffIndex int
+}
+
+// Insertions returns the positions where a pickup and a drop-off stop can be
+// inserted among the trip's stops, the first and last stops staying in place,
+// from the one that adds the shortest great-circle distance to the longest.
+// When no drop-off point is given, only the pickup stop is inserted, such as
+// for a stop proposed by a passenger.
+func (t *Trip) Insertions(pickup, dropOff *Point) []*Insertion {
+	insertions := make([]*Insertion, 0)
+	for i := 1; i < len(t.Stops); i++ {
+		if dropOff == nil {
+			insertions = append(insertions, &Insertion{i, 0})
+			continue
+		}
+
+		for j := i + 1; j <= len(t.Stops); j++ {
+			insertions = append(insertions, &Insertion{i, j})
+		}
+	}
+
+	added := make(map[*Insertion]float64, len(insertions))
+	for _, insertion := range insertions {
+		points := t.insertedPoints(pickup, dropOff, insertion)
+		distance := 0.0
+		for k := 1; k < len(points); k++ {
+			distance += points[k-1].DistanceTo(points[k])
+		}
+		added[insertion] = distance
+	}
+
+	sort.SliceStable(insertions, func(i, j int) bool {
+		return added[insertions[i]] < added[insertions[j]]
+	})
+
+	return insertions
+}
+
+func (t *Trip) insertedPoints(pickup, dropOff *Point, insertion *Insertion) []*Point {
+	points := make([]*Point, 0, len(t.Stops)+2)
+	for _, s := range t.Stops {
+		points = append(points, s.Point)
+	}
+
+	points = append(points[:insertion.PickupIndex], append([]*Point{pickup}, points[insertion.PickupIndex:]...)...)
+	if dropOff != nil {
+		points = append(points[:insertion.DropOffIndex], append([]*Point{dropOff}, points[insertion.DropOffIndex:]...)...)
+	}
+
+	return points
+}
+
+// WithInsertedStops returns a copy of the trip whose stops include the pickup
+// and drop-off stops at the given position. The inserted stops have the
+// seats available on the stop preceding them. When no drop-off stop is given,
+// only the pickup stop is inserted.
+func (t *Trip) WithInsertedStops(pickup, dropOff *Stop, insertion *Insertion) *Trip {
+	stops := make([]*Stop, 0, len(t.Stops)+2)
+	for _, s := range t.Stops {
+		stop := *s
+		stops = append(stops, &stop)
+	}
+
+	pickup.Seats = stops[insertion.PickupIndex-1].Seats
+	stops = append(stops[:insertion.PickupIndex], append([]*Stop{pickup}, stops[insertion.PickupIndex:]...)...)
+
+	if dropOff != nil {
+		dropOff.Seats = stops[insertion.DropOffIndex-1].Seats
+		stops = append(stops[:insertion.DropOffIndex], append([]*Stop{dropOff}, stops[insertion.DropOffIndex:]...)...)
+	}
+
+	trip := *t
+	trip.Stops = stops
+	trip.OptimizeStops = false
+
+	return &trip
+}
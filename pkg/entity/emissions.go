@@ -0,0 +1,164 @@
+package entity
+
+const (
+	// VehicleFuelGasoline represents a vehicle running on gasoline.
+	VehicleFuelGasoline = "gasoline"
+
+	// VehicleFuelDiesel represents a vehicle running on diesel.
+	VehicleFuelDiesel = "diesel"
+
+	// VehicleFuelHybrid represents a hybrid vehicle, running on gasoline.
+	VehicleFuelHybrid = "hybrid"
+
+	// VehicleFuelElectric represents a vehicle running on electricity, whose
+	// consumption is in kWh per 100 kilometers.
+	VehicleFuelElectric = "electric"
+
+	// GasolineEmissionFactor represents the CO2 emitted (in kilograms) by
+	// burning a liter of gasoline.
+	GasolineEmissionFactor = 2.31
+
+	// DieselEmissionFactor represents the CO2 emitted (in kilograms) by
+	// burning a liter of diesel.
+	DieselEmissionFactor = 2.68
+
+	// ElectricityEmissionFactor represents the CO2 emitted (in kilograms) to
+	// produce a kWh of electricity.
+	ElectricityEmissionFactor = 0.1
+
+	// SoloEmissionsPerKilometer represents the CO2 emitted (in kilograms) per
+	// kilometer by an average passenger car, which is what a passenger would
+	// emit driving alone instead of carpooling.
+	SoloEmissionsPerKilometer = 0.192
+)
+
+// defaultConsumptions contains the consumption (in liters or kWh per 100
+// kilometers) of vehicles that do not specify theirs, by fuel type.
+var defaultConsumptions = map[string]float64{
+	VehicleFuelGasoline: 8.0,
+	VehicleFuelDiesel:   7.0,
+	VehicleFuelHybrid:   5.0,
+	VehicleFuelElectric: 18.0,
+}
+
+// IsVehicleFuel checks whether the given fuel type is a known one.
+func IsVehicleFuel(fuel string) bool {
+	_, ok := defaultConsumptions[fuel]
+	return ok
+}
+
+// DefaultConsumption returns the consumption (in liters or kWh per 100
+// kilometers) of the vehicles running on the given fuel that do not specify
+// theirs.
+func DefaultConsumption(fuel string) float64 {
+	return defaultConsumptions[fuel]
+}
+
+// Fuel returns the vehicle's fuel type. Vehicles that do not specify it run on
+// electricity if they have the electric feature, or on gasoline otherwise.
+func (v *Vehicle) Fuel() string {
+	if v.FuelType != "" {
+		return v.FuelType
+	}
+
+	if v.HasFeature(VehicleFeatureElectric) {
+		return VehicleFuelElectric
+	}
+
+	return VehicleFuelGasoline
+}
+
+// EmissionsPerKilometer returns the CO2 emitted (in kilograms) per kilometer
+// driven with the vehicle, estimated from its fuel type and consumption.
+func (v *Vehicle) EmissionsPerKilometer() float64 {
+	fuel := v.Fuel()
+
+	consumption := v.Consumption
+	if consumption <= 0 {
+		consumption = defaultConsumptions[fuel]
+	}
+
+	factor := GasolineEmissionFactor
+	switch fuel {
+	case VehicleFuelDiesel:
+		factor = DieselEmissionFactor
+	case VehicleFuelElectric:
+		factor = ElectricityEmissionFactor
+	}
+
+	return consumption / 100 * factor
+}
+
+// emissionsPerKilometer returns the CO2 emitted per kilometer during the trip,
+// assuming an average gasoline car if its vehicle is unknown.
+func (t *Trip) emissionsPerKilometer() float64 {
+	if t.Vehicle == nil {
+		return (&Vehicle{}).EmissionsPerKilometer()
+	}
+
+	return t.Vehicle.EmissionsPerKilometer()
+}
+
+// EstimateEmissions updates the trip's emissions from its total distance and
+// its vehicle.
+func (t *Trip) EstimateEmissions() {
+	t.Emissions = float64(t.TotalDistance) / 1000 * t.emissionsPerKilometer()
+}
+
+// SegmentDistance returns the distance (in meters) driven between the stops
+// at the given indices, from the distance of each leg. If the legs' distances
+// are unknown, the trip's total distance is split evenly between the legs.
+func (t *Trip) SegmentDistance(from, to int) int {
+	distance := 0
+	known := true
+	for i := from; i < to; i++ {
+		if t.Stops[i].Distance == 0 {
+			known = false
+			break
+		}
+		distance += t.Stops[i].Distance
+	}
+
+	if known || len(t.Stops) < 2 {
+		return distance
+	}
+
+	return t.TotalDistance * (to - from) / (len(t.Stops) - 1)
+}
+
+// EstimateReservationEmissions updates the reservation's distance, its share
+// of the trip's emissions and the CO2 it saves compared to each passenger
+// driving alone. The emissions of each leg are shared between the driver and
+// the passengers in the car, including the reservation's.
+func (t *Trip) EstimateReservationEmissions(r *Reservation) error {
+	from := t.IndexOfStop(r.SourceID)
+	to := t.IndexOfStop(r.DestinationID)
+	if from < 0 || to < 0 || from >= to {
+		return ValidationError{"source and destination must be stops of the trip, in order"}
+	}
+
+	perKilometer := t.emissionsPerKilometer()
+
+	r.Distance = t.SegmentDistance(from, to)
+	r.Emissions = 0
+	for i := from; i < to; i++ {
+		// The seats available on the leg do not include the reservation yet
+		passengers := t.Seats - t.Stops[i].Seats + r.Seats
+		legEmissions := float64(t.SegmentDistance(i, i+1)) / 1000 * perKilometer
+		r.Emissions += legEmissions * float64(r.Seats) / float64(passengers+1)
+	}
+
+	r.EmissionsSaved = float64(r.Distance)/1000*SoloEmissionsPerKilometer*float64(r.Seats) - r.Emissions
+
+	return nil
+}
+
+// EmissionsSummary contains the environmental impact of a user's
+// reservations.
+type EmissionsSummary struct {
+	UserID         ID      `json:"userId"`
+	Reservations   int     `json:"reservations"`
+	Distance       int     `json:"distance"`
+	Emissions      float64 `json:"emissions"`
+	EmissionsSaved float64 `json:"emissionsSaved"`
+}
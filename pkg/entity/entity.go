@@ -1,6 +1,10 @@
 package entity
 
-import "strings"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
 
 // An ID is an entity's unique identifier.
 type ID string
@@ -8,6 +12,19 @@ type ID string
 // NilID is the zero value for an ID.
 var NilID ID
 
+// NewID creates a new unique identifier, for the entities that are embedded in
+// another one, such as a trip's stops. It has the same format as the
+// identifiers generated by the database.
+func NewID() ID {
+	b := make([]byte, 12)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return ID(hex.EncodeToString(b))
+}
+
 // NewIDFromHex creates a new unique identifier from a hex string.
 func NewIDFromHex(hex string) ID {
 	return ID(hex)
@@ -0,0 +1,145 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Facets contains the number of trips matching a search, broken down by
+// some of their characteristics.
+type Facets struct {
+	Count          int            `json:"count"`
+	Luggages       []*FacetCount  `json:"luggages"`
+	Animals        []*FacetCount  `json:"animals"`
+	DepartureHours []*FacetBucket `json:"departureHours"`
+	Prices         []*FacetBucket `json:"prices"`
+}
+
+// FacetCount contains the number of trips that have a given value.
+type FacetCount struct {
+	Value int `json:"value"`
+	Count int `json:"count"`
+}
+
+// FacetBucket contains the number of trips that have a value within a range,
+// which includes its minimum and excludes its maximum. The last bucket has no
+// maximum.
+type FacetBucket struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int      `json:"count"`
+}
+
+// FacetFilters contains the filters used to compute facets, along with the
+// time zone used to group trips by departure hour.
+type FacetFilters struct {
+	Filters
+	Timezone string `json:"timezone,omitempty" schema:"timezone,ommitempty"`
+}
+
+var (
+	// DepartureHourBoundaries represents the hours at which the departure hour
+	// buckets start and end.
+	DepartureHourBoundaries = []float64{0, 6, 9, 12, 15, 18, 21, 24}
+
+	// PriceBoundaries represents the prices per seat (in dollars) at which
+	// the price buckets start and end. Prices above the last boundary are
+	// counted in an open ended bucket.
+	PriceBoundaries = []float64{0, 10, 20, 30, 50}
+)
+
+// DefaultTimezone represents the time zone used to group trips by departure
+// hour when none is specified.
+const DefaultTimezone = "UTC"
+
+// Validate validates that the facet filters are filled out correctly.
+func (f *FacetFilters) Validate() error {
+	err := f.Filters.Validate()
+	if err != nil {
+		return err
+	}
+
+	if f.Timezone != "" {
+		_, err := time.LoadLocation(f.Timezone)
+		if err != nil {
+			return ValidationError{fmt.Sprintf("unknown timezone \"%s\"", f.Timezone)}
+		}
+	}
+
+	return nil
+}
+
+// NewFacets counts the given trips, broken down by luggage size, animals,
+// departure hour in the given location and price per seat for a new
+// passenger. The trips without details are not counted by luggage size or
+// animals.
+func NewFacets(trips []*Trip, loc *time.Location) *Facets {
+	luggages := make(map[int]int)
+	animals := make(map[int]int)
+	departureHours := make(map[float64]int)
+	prices := make(map[float64]int)
+	for _, t := range trips {
+		if t.Details != nil {
+			luggages[t.Details.Luggages]++
+			animals[t.Details.Animals]++
+		}
+
+		departureHours[bucketMin(float64(t.LeaveAt.In(loc).Hour()), DepartureHourBoundaries)]++
+		prices[bucketMin(t.FareFor(1), PriceBoundaries)]++
+	}
+
+	return &Facets{
+		Count:          len(trips),
+		Luggages:       newFacetCounts(luggages),
+		Animals:        newFacetCounts(animals),
+		DepartureHours: newFacetBuckets(departureHours, DepartureHourBoundaries, false),
+		Prices:         newFacetBuckets(prices, PriceBoundaries, true),
+	}
+}
+
+// bucketMin returns the minimum of the bucket the value falls in, which is the
+// last boundary that is not greater than the value.
+func bucketMin(value float64, boundaries []float64) float64 {
+	min := boundaries[0]
+	for _, b := range boundaries {
+		if b <= value {
+			min = b
+		}
+	}
+
+	return min
+}
+
+func newFacetCounts(counts map[int]int) []*FacetCount {
+	facetCounts := make([]*FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facetCounts = append(facetCounts, &FacetCount{value, count})
+	}
+
+	sort.Slice(facetCounts, func(i, j int) bool {
+		return facetCounts[i].Value < facetCounts[j].Value
+	})
+
+	return facetCounts
+}
+
+// Creates a bucket for every range between the boundaries, even the empty
+// ones. If the buckets are open ended, an additional bucket starts at the last
+// boundary.
+func newFacetBuckets(counts map[float64]int, boundaries []float64, openEnded bool) []*FacetBucket {
+	buckets := make([]*FacetBucket, 0, len(boundaries))
+	for i, min := range boundaries {
+		b := &FacetBucket{Min: min, Count: counts[min]}
+		if i < len(boundaries)-1 {
+			max := boundaries[i+1]
+			b.Max = &max
+		} else if !openEnded {
+			break
+		}
+
+		buckets = append(buckets, b)
+	}
+
+	return buckets
+}
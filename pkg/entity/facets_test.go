@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewFacets(t *testing.T) {
+	montreal, err := time.LoadLocation("America/Montreal")
+	if err != nil {
+		t.Skipf("time zone database unavailable (%s)", err)
+	}
+
+	trips := []*Trip{
+		{LeaveAt: time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC), TotalTripPrice: 5, Details: &Details{Luggages: 2, Animals: 1}},
+		{LeaveAt: time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC), TotalTripPrice: 30, ReservationsCount: 1, Details: &Details{Luggages: 1}},
+		{LeaveAt: time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC), TotalTripPrice: 80},
+	}
+
+	f := NewFacets(trips, montreal)
+
+	if f.Count != 3 {
+		t.Errorf("count = %d, want 3", f.Count)
+	}
+
+	if len(f.Luggages) != 2 || f.Luggages[0].Value != 1 || f.Luggages[1].Value != 2 {
+		t.Errorf("luggages = %v, want the values 1 and 2 in order", f.Luggages)
+	}
+
+	if len(f.Animals) != 2 || f.Animals[0].Count != 1 || f.Animals[1].Count != 1 {
+		t.Errorf("animals = %v, want one trip for 0 and 1", f.Animals)
+	}
+
+	// 8:30 and 9:00 in Montreal fall in the 6 and 9 buckets, 22:00 the day
+	// before in the 21 bucket
+	wantHours := []int{0, 1, 1, 0, 0, 0, 1}
+	if len(f.DepartureHours) != len(wantHours) {
+		t.Fatalf("len(departureHours) = %d, want %d", len(f.DepartureHours), len(wantHours))
+	}
+	for i, want := range wantHours {
+		if f.DepartureHours[i].Count != want {
+			t.Errorf("departureHours[%v] = %d, want %d", f.DepartureHours[i].Min, f.DepartureHours[i].Count, want)
+		}
+	}
+
+	// Prices per seat of 5, 15 and 80, the last one in the open ended bucket
+	wantPrices := []int{1, 1, 0, 0, 1}
+	if len(f.Prices) != len(wantPrices) {
+		t.Fatalf("len(prices) = %d, want %d", len(f.Prices), len(wantPrices))
+	}
+	for i, want := range wantPrices {
+		if f.Prices[i].Count != want {
+			t.Errorf("prices[%v] = %d, want %d", f.Prices[i].Min, f.Prices[i].Count, want)
+		}
+	}
+	if f.Prices[len(f.Prices)-1].Max != nil {
+		t.Error("last price bucket has a max, want none")
+	}
+}
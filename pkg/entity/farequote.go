@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"fmt"
+)
+
+// A FareQuoteRequest contains the segment of a trip and the number of seats a
+// passenger would like to reserve.
+type FareQuoteRequest struct {
+	SourceID      string `json:"sourceId,omitempty" schema:"sourceId,ommitempty"`
+	DestinationID string `json:"destinationId,omitempty" schema:"destinationId,ommitempty"`
+	Seats         *int   `json:"seats,omitempty" schema:"seats,ommitempty"`
+}
+
+// A FareQuote contains the price a passenger would pay to reserve seats on a
+// segment of a trip, and how it would change as other passengers join.
+type FareQuote struct {
+	SourceID       ID      `json:"sourceId"`
+	DestinationID  ID      `json:"destinationId"`
+	Seats          int     `json:"seats"`
+	AvailableSeats int     `json:"availableSeats"`
+	Fare           float64 `json:"fare"`
+	PricePerSeat   float64 `json:"pricePerSeat"`
+
+	// Projections are the fares once each of the other seats available on
+	// the segment is reserved, the trip's price being split between more
+	// seats.
+	Projections []*FareProjection `json:"projections"`
+}
+
+// A FareProjection is the fare a passenger would pay once the given number of
+// seats, including theirs, are reserved on the trip.
+type FareProjection struct {
+	ReservedSeats int     `json:"reservedSeats"`
+	Fare          float64 `json:"fare"`
+	PricePerSeat  float64 `json:"pricePerSeat"`
+}
+
+// Validate validates that the fare quote request's required fields are filled
+// out correctly.
+func (r *FareQuoteRequest) Validate() error {
+	if r.SourceID == "" {
+		return ValidationError{"sourceId is missing"}
+	}
+
+	if r.DestinationID == "" {
+		return ValidationError{"destinationId is missing"}
+	}
+
+	if r.Seats == nil {
+		return ValidationError{"seats is missing"}
+	}
+
+	if *r.Seats < MinimumSeats || *r.Seats > MaximumSeats {
+		return ValidationError{fmt.Sprintf("number of seats must be between %d and %d", MinimumSeats, MaximumSeats)}
+	}
+
+	return nil
+}
+
+// QuoteFare computes the fare a passenger would pay to reserve the given
+// number of seats between the stops with the given IDs, which must be in the
+// trip's order and have enough seats available between them.
+func (t *Trip) QuoteFare(sourceID, destinationID ID, seats int) (*FareQuote, error) {
+	from := t.IndexOfStop(sourceID)
+	if from < 0 {
+		return nil, ValidationError{fmt.Sprintf("trip has no stop with ID \"%s\"", sourceID)}
+	}
+
+	to := t.IndexOfStop(destinationID)
+	if to < 0 {
+		return nil, ValidationError{fmt.Sprintf("trip has no stop with ID \"%s\"", destinationID)}
+	}
+
+	if from >= to {
+		return nil, ValidationError{"source must come before destination"}
+	}
+
+	available := t.AvailableSeats(from, to)
+	if available < seats {
+		return nil, ValidationError{fmt.Sprintf("only %d seats are available between these stops", available)}
+	}
+
+	// Each projection is the fare once other passengers have reserved some
+	// of the remaining seats
+	projections := make([]*FareProjection, 0, available-seats+1)
+	projected := *t
+	for others := 0; others <= available-seats; others++ {
+		projected.ReservationsCount = t.ReservationsCount + others
+		fare := projected.FareFor(seats)
+		projections = append(projections, &FareProjection{
+			ReservedSeats: projected.ReservationsCount + seats,
+			Fare:          fare,
+			PricePerSeat:  fare / float64(seats),
+		})
+	}
+
+	return &FareQuote{
+		SourceID:       sourceID,
+		DestinationID:  destinationID,
+		Seats:          seats,
+		AvailableSeats: available,
+		Fare:           projections[0].Fare,
+		PricePerSeat:   projections[0].PricePerSeat,
+		Projections:    projections,
+	}, nil
+}
@@ -2,22 +2,29 @@ package entity
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
 // Filters contains a filter's information.
 type Filters struct {
-	DriverID             string    `schema:"driverId,ommitempty"`
-	Seats                *int      `schema:"seats,ommitempty"`
-	LeaveAt              time.Time `schema:"leaveAt,ommitempty"`
-	ArriveBy             time.Time `schema:"arriveBy,ommitempty"`
-	DetailsAnimals       *int      `schema:"detailsAnimals,ommitempty"`
-	DetailsLuggages      *int      `schema:"detailsLuggages,ommitempty"`
-	RadiusThresh         *int      `schema:"radiusThresh,ommitempty"`
-	SourceLatitude       *float64  `schema:"sourceLatitude,ommitempty"`
-	SourceLongitude      *float64  `schema:"sourceLongitude,ommitempty"`
-	DestinationLatitude  *float64  `schema:"destinationLatitude,ommitempty"`
-	DestinationLongitude *float64  `schema:"destinationLongitude,ommitempty"`
+	DriverID             string    `json:"driverId,omitempty" schema:"driverId,ommitempty"`
+	Seats                *int      `json:"seats,omitempty" schema:"seats,ommitempty"`
+	LeaveAt              time.Time `json:"leaveAt,omitempty" schema:"leaveAt,ommitempty"`
+	ArriveBy             time.Time `json:"arriveBy,omitempty" schema:"arriveBy,ommitempty"`
+	DetailsAnimals       *int      `json:"detailsAnimals,omitempty" schema:"detailsAnimals,ommitempty"`
+	DetailsLuggages      *int      `json:"detailsLuggages,omitempty" schema:"detailsLuggages,ommitempty"`
+	RadiusThresh         *int      `json:"radiusThresh,omitempty" schema:"radiusThresh,ommitempty"`
+	SourceLatitude       *float64  `json:"sourceLatitude,omitempty" schema:"sourceLatitude,ommitempty"`
+	SourceLongitude      *float64  `json:"sourceLongitude,omitempty" schema:"sourceLongitude,ommitempty"`
+	DestinationLatitude  *float64  `json:"destinationLatitude,omitempty" schema:"destinationLatitude,ommitempty"`
+	DestinationLongitude *float64  `json:"destinationLongitude,omitempty" schema:"destinationLongitude,ommitempty"`
+	Mode                 string    `json:"mode,omitempty" schema:"mode,ommitempty"`
+	Query                string    `json:"q,omitempty" schema:"q,ommitempty"`
+	VehicleMake          string    `json:"vehicleMake,omitempty" schema:"vehicleMake,ommitempty"`
+	VehicleModel         string    `json:"vehicleModel,omitempty" schema:"vehicleModel,ommitempty"`
+	VehicleMinYear       *int      `json:"vehicleMinYear,omitempty" schema:"vehicleMinYear,ommitempty"`
+	VehicleFeatures      []string  `json:"vehicleFeatures,omitempty" schema:"vehicleFeatures,ommitempty"`
 }
 
 const (
@@ -35,6 +42,22 @@ const (
 
 	// MinimumRadiusThresh represents the minimum value for radius threshold
 	MinimumRadiusThresh = 0
+
+	// SearchModeStops represents a search that matches trips with stops near
+	// the source and destination.
+	SearchModeStops = "stops"
+
+	// SearchModeCorridor represents a search that matches trips whose route
+	// passes near the source and destination.
+	SearchModeCorridor = "corridor"
+
+	// DefaultRadiusThresh represents the radius threshold (in meters) used
+	// when none is specified
+	DefaultRadiusThresh = 2000
+
+	// TimeThreshold represents the time threshold for leaveAt or arriveBy (in
+	// hours)
+	TimeThreshold = 12
 )
 
 // Validate validates that the filters's required fields are filled out correctly.
@@ -75,5 +98,156 @@ func (f *Filters) Validate() error {
 		return ValidationError{"invalid destination latitude value"}
 	}
 
+	if f.Mode != "" && f.Mode != SearchModeStops && f.Mode != SearchModeCorridor {
+		return ValidationError{fmt.Sprintf("mode must be \"%s\" or \"%s\"", SearchModeStops, SearchModeCorridor)}
+	}
+
+	if f.Mode == SearchModeCorridor && !f.HasLocation() {
+		return ValidationError{"corridor search requires a source or a destination"}
+	}
+
+	if f.VehicleMinYear != nil && *f.VehicleMinYear < YearMinimum {
+		return ValidationError{fmt.Sprintf("vehicleMinYear must be greater than %d", YearMinimum)}
+	}
+
+	for _, feature := range f.VehicleFeatures {
+		if !IsVehicleFeature(feature) {
+			return ValidationError{fmt.Sprintf("unknown vehicle feature \"%s\"", feature)}
+		}
+	}
+
 	return nil
 }
+
+// Source returns the point from which the passenger wants to leave, or nil if
+// the filters do not specify one.
+func (f *Filters) Source() *Point {
+	if f.SourceLatitude == nil || f.SourceLongitude == nil {
+		return nil
+	}
+
+	return &Point{Latitude: *f.SourceLatitude, Longitude: *f.SourceLongitude}
+}
+
+// Destination returns the point where the passenger wants to go, or nil if the
+// filters do not specify one.
+func (f *Filters) Destination() *Point {
+	if f.DestinationLatitude == nil || f.DestinationLongitude == nil {
+		return nil
+	}
+
+	return &Point{Latitude: *f.DestinationLatitude, Longitude: *f.DestinationLongitude}
+}
+
+// HasLocation reports whether the filters specify a source or a destination.
+func (f *Filters) HasLocation() bool {
+	return f.Source() != nil || f.Destination() != nil
+}
+
+// Radius returns the radius threshold in meters, or the default radius
+// threshold if the filters do not specify one.
+func (f *Filters) Radius() float64 {
+	if f.RadiusThresh == nil {
+		return DefaultRadiusThresh
+	}
+
+	return float64(*f.RadiusThresh)
+}
+
+// Accepts checks whether the trip satisfies the filters' criteria that do not
+// depend on the passenger's location, such as the driver, the vehicle, the
+// trip's details and the names of its source and destination.
+func (f *Filters) Accepts(t *Trip) bool {
+	if t.Full {
+		return false
+	}
+
+	if f.DriverID != "" && f.DriverID != t.DriverID.Hex() {
+		return false
+	}
+
+	if f.DetailsAnimals != nil && (t.Details == nil || t.Details.Animals != *f.DetailsAnimals) {
+		return false
+	}
+
+	if f.DetailsLuggages != nil && (t.Details == nil || t.Details.Luggages < *f.DetailsLuggages) {
+		return false
+	}
+
+	if !f.acceptsVehicle(t.Vehicle) {
+		return false
+	}
+
+	if f.Query != "" && !f.acceptsText(t) {
+		return false
+	}
+
+	return true
+}
+
+// acceptsText checks whether the query is found in the names of the trip's
+// first and last stops. The intermediate stops are ignored, since a trip that
+// only passes through a city is not a trip from or to it.
+func (f *Filters) acceptsText(t *Trip) bool {
+	if len(t.Stops) == 0 {
+		return false
+	}
+
+	names := make([]string, 0, 2)
+	for _, s := range []*Stop{t.Stops[0], t.Stops[len(t.Stops)-1]} {
+		if s.Point != nil {
+			names = append(names, s.Point.Name)
+		}
+	}
+
+	return matchesText(f.Query, names...)
+}
+
+func (f *Filters) acceptsVehicle(v *Vehicle) bool {
+	if f.VehicleMake == "" && f.VehicleModel == "" && f.VehicleMinYear == nil && len(f.VehicleFeatures) == 0 {
+		return true
+	}
+
+	if v == nil {
+		return false
+	}
+
+	if f.VehicleMake != "" && !strings.EqualFold(f.VehicleMake, v.Make) {
+		return false
+	}
+
+	if f.VehicleModel != "" && !strings.EqualFold(f.VehicleModel, v.Model) {
+		return false
+	}
+
+	if f.VehicleMinYear != nil && v.Year < *f.VehicleMinYear {
+		return false
+	}
+
+	for _, feature := range f.VehicleFeatures {
+		if !v.HasFeature(feature) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// acceptsTimes checks whether the passenger is picked up around the time they
+// want to leave at, and dropped off around the time they want to arrive by.
+func (f *Filters) acceptsTimes(pickup, dropOff *Stop) bool {
+	if !f.LeaveAt.IsZero() && !withinTimeThreshold(pickup.Departure(), f.LeaveAt) {
+		return false
+	}
+
+	if !f.ArriveBy.IsZero() && !withinTimeThreshold(dropOff.Arrival(), f.ArriveBy) {
+		return false
+	}
+
+	return true
+}
+
+func withinTimeThreshold(actual, requested time.Time) bool {
+	return actual.After(requested.Add(time.Hour*(-TimeThreshold))) &&
+		actual.Before(requested.Add(time.Hour*TimeThreshold))
+}
@@ -0,0 +1,154 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// An Itinerary contains the trips a passenger can take one after the other to
+// get from a source to a destination, when no single trip connects them.
+type Itinerary struct {
+	Legs             []*ItineraryLeg `json:"legs"`
+	Price            float64         `json:"price"`
+	LeaveAt          time.Time       `json:"leaveAt"`
+	ArriveBy         time.Time       `json:"arriveBy"`
+	Duration         int             `json:"duration"`
+	TransferWait     int             `json:"transferWait"`
+	TransferDistance float64         `json:"transferDistance"`
+}
+
+// An ItineraryLeg contains the trip taken during one part of an itinerary,
+// along with the stops where the passenger is picked up and dropped off.
+type ItineraryLeg struct {
+	Trip    *Trip   `json:"trip"`
+	Pickup  *Stop   `json:"pickup"`
+	DropOff *Stop   `json:"dropOff"`
+	Fare    float64 `json:"fare"`
+}
+
+// ItineraryFilters contains the filters used to search itineraries, along
+// with the constraints on the transfer between two trips.
+type ItineraryFilters struct {
+	Filters
+	MinTransferWait      *int `json:"minTransferWait,omitempty" schema:"minTransferWait,ommitempty"`
+	MaxTransferWait      *int `json:"maxTransferWait,omitempty" schema:"maxTransferWait,ommitempty"`
+	TransferRadiusThresh *int `json:"transferRadiusThresh,omitempty" schema:"transferRadiusThresh,ommitempty"`
+}
+
+const (
+	// DefaultMinTransferWait represents the minimum time (in minutes) to wait
+	// between two trips when none is specified.
+	DefaultMinTransferWait = 5
+
+	// DefaultMaxTransferWait represents the maximum time (in minutes) to wait
+	// between two trips when none is specified.
+	DefaultMaxTransferWait = 60
+
+	// DefaultTransferRadiusThresh represents the maximum distance (in meters)
+	// between the stops where a passenger transfers from a trip to another
+	// when none is specified.
+	DefaultTransferRadiusThresh = 500
+)
+
+// Validate validates that the itinerary filters are filled out correctly.
+func (f *ItineraryFilters) Validate() error {
+	err := f.Filters.Validate()
+	if err != nil {
+		return err
+	}
+
+	if f.Source() == nil || f.Destination() == nil {
+		return ValidationError{"itinerary search requires a source and a destination"}
+	}
+
+	if f.MinTransferWait != nil && *f.MinTransferWait < 0 {
+		return ValidationError{"minTransferWait must be greater than 0"}
+	}
+
+	if f.MaxTransferWait != nil && *f.MaxTransferWait < 0 {
+		return ValidationError{"maxTransferWait must be greater than 0"}
+	}
+
+	if f.TransferWaitRange().Min > f.TransferWaitRange().Max {
+		return ValidationError{"minTransferWait must be less than maxTransferWait"}
+	}
+
+	if f.TransferRadiusThresh != nil && *f.TransferRadiusThresh <= MinimumRadiusThresh {
+		return ValidationError{fmt.Sprintf("transferRadiusThresh must be greater than %d", MinimumRadiusThresh)}
+	}
+
+	return nil
+}
+
+// A DurationRange is a range of durations, bounds included.
+type DurationRange struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Contains checks whether the duration is within the range.
+func (r DurationRange) Contains(d time.Duration) bool {
+	return d >= r.Min && d <= r.Max
+}
+
+// TransferWaitRange returns the range of time a passenger accepts to wait
+// between two trips, or the default range if the filters do not specify one.
+func (f *ItineraryFilters) TransferWaitRange() DurationRange {
+	r := DurationRange{DefaultMinTransferWait * time.Minute, DefaultMaxTransferWait * time.Minute}
+
+	if f.MinTransferWait != nil {
+		r.Min = time.Duration(*f.MinTransferWait) * time.Minute
+	}
+
+	if f.MaxTransferWait != nil {
+		r.Max = time.Duration(*f.MaxTransferWait) * time.Minute
+	}
+
+	return r
+}
+
+// TransferRadius returns the maximum distance in meters between the stops
+// where a passenger transfers, or the default distance if the filters do not
+// specify one.
+func (f *ItineraryFilters) TransferRadius() float64 {
+	if f.TransferRadiusThresh == nil {
+		return DefaultTransferRadiusThresh
+	}
+
+	return float64(*f.TransferRadiusThresh)
+}
+
+// An ItineraryReservation contains the reservations for each leg of an
+// itinerary, which are either all made or not at all.
+type ItineraryReservation struct {
+	Reservations []*Reservation `json:"reservations"`
+}
+
+// Validate validates that the itinerary reservation's required fields are
+// filled out correctly.
+func (r *ItineraryReservation) Validate() error {
+	if len(r.Reservations) == 0 {
+		return ValidationError{"missing reservations"}
+	}
+
+	for i, res := range r.Reservations {
+		if res == nil {
+			return ValidationError{"reservation is missing"}
+		}
+
+		err := res.Validate()
+		if err != nil {
+			return err
+		}
+
+		if i > 0 && res.UserID != r.Reservations[0].UserID {
+			return ValidationError{"all reservations must be for the same user"}
+		}
+
+		if i > 0 && res.Seats != r.Reservations[0].Seats {
+			return ValidationError{"all reservations must be for the same number of seats"}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package entity
+
+// Match contains the information on how a trip matches a passenger's search,
+// such as where the passenger would be picked up and dropped off.
+type Match struct {
+	PickupID        ID      `json:"pickupId"`
+	DropOffID       ID      `json:"dropOffId"`
+	PickupDistance  float64 `json:"pickupDistance"`
+	DropOffDistance float64 `json:"dropOffDistance"`
+	PickupPoint     *Point  `json:"pickupPoint,omitempty"`
+	DropOffPoint    *Point  `json:"dropOffPoint,omitempty"`
+	Score           *Score  `json:"score,omitempty"`
+}
+
+// Score contains a search result's relevance score along with the components
+// that were used to compute it.
+type Score struct {
+	Value           float64         `json:"value"`
+	WalkingDistance *ScoreComponent `json:"walkingDistance"`
+	TimeDifference  *ScoreComponent `json:"timeDifference"`
+	PricePerSeat    *ScoreComponent `json:"pricePerSeat"`
+	RemainingSeats  *ScoreComponent `json:"remainingSeats"`
+}
+
+// ScoreComponent contains a measured value (ex. a distance in meters), its
+// normalized value between 0 and 1, and the weight it was given in a score.
+type ScoreComponent struct {
+	Measure float64 `json:"measure"`
+	Value   float64 `json:"value"`
+	Weight  float64 `json:"weight"`
+}
+
+// Match finds where a passenger searching with the filters would be picked up
+// and dropped off on the given trip, according to the filters' search mode. If
+// the trip does not match the filters, nil is returned.
+func (f *Filters) Match(t *Trip) *Match {
+	if t == nil || len(t.Stops) < 2 || !f.Accepts(t) {
+		return nil
+	}
+
+	seats := f.requestedSeats()
+	if f.Mode == SearchModeCorridor {
+		return f.matchCorridor(t, seats)
+	}
+
+	return f.matchStops(t, seats)
+}
+
+// HasSeats checks whether the trip has enough seats left on at least one of
+// its legs for a passenger searching with the filters, wherever they are
+// picked up and dropped off.
+func (f *Filters) HasSeats(t *Trip) bool {
+	seats := f.requestedSeats()
+	for i := 0; i < len(t.Stops)-1; i++ {
+		if t.AvailableSeats(i, i+1) >= seats {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestedSeats returns the number of seats the passenger wants to reserve,
+// which is at least the minimum number of seats.
+func (f *Filters) requestedSeats() int {
+	if f.Seats != nil && *f.Seats > MinimumSeats {
+		return *f.Seats
+	}
+
+	return MinimumSeats
+}
+
+// matchStops finds the stops where a passenger searching with the filters
+// would be picked up and dropped off on the given trip.
+//
+// The pickup and drop-off stops are the ones closest to the filters' source
+// and destination, in that order. If the trip has no stops within the radius
+// threshold of the source or destination, or it does not have enough seats
+// left between them, nil is returned.
+func (f *Filters) matchStops(t *Trip, seats int) *Match {
+	source := f.Source()
+	destination := f.Destination()
+	radius := f.Radius()
+
+	var m *Match
+	for i := 0; i < len(t.Stops)-1; i++ {
+		pickupDistance := distanceToStop(source, t.Stops[i])
+		if pickupDistance > radius {
+			continue
+		}
+
+		for j := len(t.Stops) - 1; j > i; j-- {
+			dropOffDistance := distanceToStop(destination, t.Stops[j])
+			if dropOffDistance > radius {
+				continue
+			}
+
+			if t.AvailableSeats(i, j) < seats {
+				continue
+			}
+
+			if !f.acceptsTimes(t.Stops[i], t.Stops[j]) {
+				continue
+			}
+
+			if m == nil || pickupDistance+dropOffDistance < m.PickupDistance+m.DropOffDistance {
+				m = &Match{
+					PickupID:        t.Stops[i].ID,
+					DropOffID:       t.Stops[j].ID,
+					PickupDistance:  pickupDistance,
+					DropOffDistance: dropOffDistance,
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+// distanceToStop returns the distance between a point and a stop. When no
+// point is given, the distance is zero since any stop is a match.
+func distanceToStop(p *Point, s *Stop) float64 {
+	if p == nil || s.Point == nil {
+		return 0
+	}
+
+	return p.DistanceTo(s.Point)
+}
@@ -2,6 +2,8 @@ package entity
 
 import (
 	"fmt"
+	"math"
+	"strings"
 )
 
 // Point contains a geolocation's information.
@@ -9,6 +11,11 @@ type Point struct {
 	Longitude float64 `json:"longitude" schema:"longitude"`
 	Latitude  float64 `json:"latitude" schema:"latitude"`
 	Name      string  `json:"name" schema:"name,ommitempty"`
+
+	// Address and PlaceID can be given instead of the coordinates, which are
+	// then resolved through geocoding.
+	Address string `json:"address,omitempty" schema:"address,ommitempty"`
+	PlaceID string `json:"placeId,omitempty" schema:"placeId,ommitempty"`
 }
 
 const (
@@ -23,6 +30,9 @@ const (
 
 	// MaximumLatitude represents the maximum latitude value.
 	MaximumLatitude = 90
+
+	// EarthRadius represents the Earth's mean radius in meters.
+	EarthRadius = 6371000.0
 )
 
 // String returns string value of Point.
@@ -30,6 +40,20 @@ func (p *Point) String() string {
 	return fmt.Sprintf("%f", p.Latitude) + ", " + fmt.Sprintf("%f", p.Longitude)
 }
 
+// DistanceTo returns the great-circle distance, in meters, between the point
+// and the given point.
+func (p *Point) DistanceTo(o *Point) float64 {
+	lat1 := p.Latitude * math.Pi / 180
+	lat2 := o.Latitude * math.Pi / 180
+	deltaLat := (o.Latitude - p.Latitude) * math.Pi / 180
+	deltaLng := (o.Longitude - p.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLng/2)*math.Sin(deltaLng/2)
+
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
 // Validate validates that the map's required fields are filled out correctly.
 func (p *Point) Validate() error {
 	if p.Longitude < MinimumLongitude || p.Longitude > MaximumLongitude {
@@ -46,3 +70,45 @@ func (p *Point) Validate() error {
 
 	return nil
 }
+
+// NeedsGeocoding checks whether the point is given as an address or a place ID
+// without coordinates, which need to be resolved through geocoding.
+func (p *Point) NeedsGeocoding() bool {
+	return p.Latitude == 0 && p.Longitude == 0 && (p.Address != "" || p.PlaceID != "")
+}
+
+// Resolve updates the point with the only candidate geocoding found for its
+// address or place ID. The candidate's formatted address is used as the
+// point's name if it does not have one.
+func (p *Point) Resolve(candidates []*Point) error {
+	query := p.PlaceID
+	if query == "" {
+		query = p.Address
+	}
+
+	if len(candidates) == 0 || candidates[0] == nil {
+		return ValidationError{fmt.Sprintf("no location found for \"%s\"", query)}
+	}
+
+	if len(candidates) > 1 {
+		addresses := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			if c != nil {
+				addresses = append(addresses, fmt.Sprintf("\"%s\"", c.Address))
+			}
+		}
+
+		return ValidationError{fmt.Sprintf("\"%s\" is ambiguous, it could be %s", query, strings.Join(addresses, ", "))}
+	}
+
+	c := candidates[0]
+	p.Latitude = c.Latitude
+	p.Longitude = c.Longitude
+	p.Address = c.Address
+	p.PlaceID = c.PlaceID
+	if p.Name == "" {
+		p.Name = c.Address
+	}
+
+	return nil
+}
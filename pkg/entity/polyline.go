@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// polylinePrecision represents the factor used to round coordinates when
+// encoding them in a polyline (5 decimal places).
+const polylinePrecision = 1e5
+
+// EncodePolyline encodes the points as a string using the encoded polyline
+// algorithm format used by Google Maps.
+func EncodePolyline(points []*Point) string {
+	var sb strings.Builder
+
+	var previousLat, previousLng int64
+	for _, p := range points {
+		lat := int64(math.Round(p.Latitude * polylinePrecision))
+		lng := int64(math.Round(p.Longitude * polylinePrecision))
+
+		encodePolylineValue(&sb, lat-previousLat)
+		encodePolylineValue(&sb, lng-previousLng)
+
+		previousLat, previousLng = lat, lng
+	}
+
+	return sb.String()
+}
+
+// DecodePolyline decodes a string in the encoded polyline algorithm format
+// used by Google Maps into points.
+func DecodePolyline(polyline string) ([]*Point, error) {
+	points := make([]*Point, 0, len(polyline)/4)
+
+	var lat, lng int64
+	for i := 0; i < len(polyline); {
+		deltaLat, n, err := decodePolylineValue(polyline[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+
+		deltaLng, n, err := decodePolylineValue(polyline[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+
+		lat += deltaLat
+		lng += deltaLng
+
+		points = append(points, &Point{
+			Latitude:  float64(lat) / polylinePrecision,
+			Longitude: float64(lng) / polylinePrecision,
+		})
+	}
+
+	return points, nil
+}
+
+func encodePolylineValue(sb *strings.Builder, value int64) {
+	value <<= 1
+	if value < 0 {
+		value = ^value
+	}
+
+	for value >= 0x20 {
+		sb.WriteByte(byte((0x20 | (value & 0x1f)) + 63))
+		value >>= 5
+	}
+	sb.WriteByte(byte(value + 63))
+}
+
+func decodePolylineValue(polyline string) (int64, int, error) {
+	var value int64
+	var shift uint
+	for i := 0; i < len(polyline); i++ {
+		b := int64(polyline[i]) - 63
+		if b < 0 {
+			return 0, 0, ValidationError{fmt.Sprintf("invalid character in polyline \"%c\"", polyline[i])}
+		}
+
+		value |= (b & 0x1f) << shift
+		shift += 5
+
+		if b < 0x20 {
+			if value&1 != 0 {
+				return ^(value >> 1), i + 1, nil
+			}
+			return value >> 1, i + 1, nil
+		}
+	}
+
+	return 0, 0, ValidationError{"polyline is truncated"}
+}
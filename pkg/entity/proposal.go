@@ -0,0 +1,68 @@
+package entity
+
+// A Proposal is a stop proposed by a passenger for a trip, which is added to
+// the trip's stops once the driver accepts it.
+type Proposal struct {
+	ID     ID     `json:"id"`
+	UserID ID     `json:"userId"`
+	Point  *Point `json:"point"`
+	Status string `json:"status"`
+
+	// StopID is the ID of the stop created when the proposal is accepted.
+	StopID ID `json:"stopId,omitempty"`
+}
+
+const (
+	// ProposalStatusPending represents a proposal waiting for the driver's
+	// answer.
+	ProposalStatusPending = "pending"
+
+	// ProposalStatusAccepted represents a proposal accepted by the driver,
+	// whose stop was added to the trip.
+	ProposalStatusAccepted = "accepted"
+
+	// ProposalStatusRejected represents a proposal rejected by the driver.
+	ProposalStatusRejected = "rejected"
+)
+
+// Validate validates that the proposal's required fields are filled out
+// correctly.
+func (p *Proposal) Validate() error {
+	if p.UserID.IsZero() {
+		return ValidationError{"User's ID is missing"}
+	}
+
+	if p.Point == nil {
+		return ValidationError{"point is missing"}
+	}
+
+	return p.Point.Validate()
+}
+
+// Proposal returns the trip's proposal with the given ID, or nil if it does
+// not exist.
+func (t *Trip) Proposal(ID ID) *Proposal {
+	for _, p := range t.Proposals {
+		if p.ID == ID {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// Answer records the driver's answer to the proposal, which must still be
+// pending.
+func (p *Proposal) Answer(accepted bool) error {
+	if p.Status != ProposalStatusPending {
+		return ValidationError{"proposal has already been answered"}
+	}
+
+	if accepted {
+		p.Status = ProposalStatusAccepted
+	} else {
+		p.Status = ProposalStatusRejected
+	}
+
+	return nil
+}
@@ -6,11 +6,18 @@ import (
 
 // Reservation contains a reservation's information.
 type Reservation struct {
+	ID            ID  `json:"id"`
 	TripID        ID  `json:"tripId"`
 	UserID        ID  `json:"userId"`
 	SourceID      ID  `json:"sourceId"`
 	DestinationID ID  `json:"destinationId"`
 	Seats         int `json:"seats"`
+
+	// Distance, Emissions and EmissionsSaved are estimated when the
+	// reservation is made, in meters and kilograms of CO2.
+	Distance       int     `json:"distance"`
+	Emissions      float64 `json:"emissions"`
+	EmissionsSaved float64 `json:"emissionsSaved"`
 }
 
 // Validate validates that the reservation's required fields are filled out correctly.
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"fmt"
+)
+
+// A RouteOption is one of the routes suggested by the routing provider to
+// drive a trip, among which the driver picks the one they will take.
+type RouteOption struct {
+	Index int `json:"index"`
+
+	// Distance and Duration are the route's distance and driving time, in
+	// meters and seconds.
+	Distance int `json:"distance"`
+	Duration int `json:"duration"`
+
+	Tolls    bool   `json:"tolls"`
+	Summary  string `json:"summary"`
+	Polyline string `json:"polyline"`
+}
+
+// SelectRoute validates that the trip's route index designates one of the
+// given route options, and that it is the route the driver was quoted when
+// the trip has a route summary. A route summary is required to pick another
+// route than the one preferred by the routing provider, since the options can
+// change between the quote and the trip's creation. The options are kept on
+// the trip when there is more than one to choose from.
+func (t *Trip) SelectRoute(options []*RouteOption) error {
+	if t.RouteIndex < 0 || t.RouteIndex >= len(options) {
+		return ValidationError{fmt.Sprintf("routeIndex must be between 0 and %d", len(options)-1)}
+	}
+
+	if t.RouteIndex > 0 && t.RouteSummary == "" {
+		return ValidationError{"routeSummary is required with a routeIndex greater than 0"}
+	}
+
+	selected := options[t.RouteIndex]
+	if t.RouteSummary != "" && t.RouteSummary != selected.Summary {
+		return ValidationError{fmt.Sprintf("route at routeIndex %d is \"%s\" instead of \"%s\", the trip must be quoted again", t.RouteIndex, selected.Summary, t.RouteSummary)}
+	}
+	t.RouteSummary = selected.Summary
+
+	t.Routes = nil
+	if len(options) > 1 {
+		t.Routes = options
+	}
+
+	return nil
+}
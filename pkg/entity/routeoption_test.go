@@ -0,0 +1,46 @@
+package entity
+
+import "testing"
+
+func TestTripSelectRoute(t *testing.T) {
+	options := []*RouteOption{
+		{Index: 0, Summary: "A-20"},
+		{Index: 1, Summary: "A-40"},
+	}
+
+	tests := []struct {
+		name         string
+		routeIndex   int
+		routeSummary string
+		wantSummary  string
+		wantErr      bool
+	}{
+		{"preferred route", 0, "", "A-20", false},
+		{"quoted route", 1, "A-40", "A-40", false},
+		{"reordered routes", 1, "A-20", "", true},
+		{"alternative without a summary", 1, "", "", true},
+		{"out of range", 2, "A-40", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := &Trip{RouteIndex: tt.routeIndex, RouteSummary: tt.routeSummary}
+
+			err := trip.SelectRoute(options)
+			if tt.wantErr {
+				if _, ok := err.(ValidationError); !ok {
+					t.Fatalf("SelectRoute() error = %v, want a ValidationError", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SelectRoute() error = %v", err)
+			}
+
+			if trip.RouteSummary != tt.wantSummary || len(trip.Routes) != len(options) {
+				t.Errorf("SelectRoute() trip = %+v, want the summary %q and the options", trip, tt.wantSummary)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package entity
+
+// Search contains a passenger's saved search, used to notify them when a new
+// trip matches it.
+type Search struct {
+	ID      ID       `json:"id"`
+	UserID  ID       `json:"userId"`
+	Filters *Filters `json:"filters"`
+}
+
+// Validate validates that the search's required fields are filled out
+// correctly.
+func (s *Search) Validate() error {
+	if s.UserID.IsZero() {
+		return ValidationError{"User's ID is missing"}
+	}
+
+	if s.Filters == nil {
+		return ValidationError{"missing filters"}
+	}
+
+	return s.Filters.Validate()
+}
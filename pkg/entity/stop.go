@@ -10,6 +10,17 @@ type Stop struct {
 	Point     *Point    `json:"point,ommitempty"`
 	Seats     int       `json:"seats,ommitempty"`
 	TimeStamp time.Time `json:"timestamp,ommitempty"`
+
+	// ArrivalTime and DepartureTime represent when the driver arrives at the
+	// stop and leaves it, which differ by the dwell time at intermediate
+	// stops.
+	ArrivalTime   time.Time `json:"arrivalTime"`
+	DepartureTime time.Time `json:"departureTime"`
+
+	// Distance and Duration describe the leg from the stop to the next one,
+	// in meters and seconds. They are 0 for the last stop.
+	Distance int `json:"distance"`
+	Duration int `json:"duration"`
 }
 
 // Validate validates that the stop's required fields are filled out correctly.
@@ -25,3 +36,23 @@ func (s *Stop) Validate() error {
 
 	return nil
 }
+
+// Arrival returns the time the driver arrives at the stop, or its timestamp
+// for stops created before arrival times were computed.
+func (s *Stop) Arrival() time.Time {
+	if s.ArrivalTime.IsZero() {
+		return s.TimeStamp
+	}
+
+	return s.ArrivalTime
+}
+
+// Departure returns the time the driver leaves the stop, or its timestamp for
+// stops created before departure times were computed.
+func (s *Stop) Departure() time.Time {
+	if s.DepartureTime.IsZero() {
+		return s.TimeStamp
+	}
+
+	return s.DepartureTime
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizeText lowercases the text and removes its accents, so that texts
+// can be compared regardless of case and diacritics (ex. "Montréal" and
+// "montreal").
+func normalizeText(text string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return sb.String()
+}
+
+// textWords splits the normalized text into its words.
+func textWords(text string) []string {
+	return strings.FieldsFunc(normalizeText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matchesText checks whether any word of the query is one of the words of the
+// given names, regardless of case and diacritics.
+func matchesText(query string, names ...string) bool {
+	words := make(map[string]bool)
+	for _, name := range names {
+		for _, w := range textWords(name) {
+			words[w] = true
+		}
+	}
+
+	for _, w := range textWords(query) {
+		if words[w] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -19,7 +19,38 @@ type Trip struct {
 	ReservationsCount int       `json:"reservationsCount"`
 	TotalTripPrice    float64   `json:"totalTripPrice"`
 	PricePerSeat      float64   `json:"pricePerSeat"`
-	TotalDistance     int       `json:"totalDistance"`
+
+	// Costs are the tolls, ferry fares and parking costs declared by the
+	// driver, which are added to the price computed from the distance.
+	Costs *Costs `json:"costs,omitempty"`
+
+	// PriceBreakdown itemizes the total price, and PricePerSeatBreakdown
+	// the price per seat.
+	PriceBreakdown        *PriceBreakdown `json:"priceBreakdown,omitempty"`
+	PricePerSeatBreakdown *PriceBreakdown `json:"pricePerSeatBreakdown,omitempty"`
+
+	TotalDistance     int         `json:"totalDistance"`
+	Polyline          string      `json:"polyline"`
+	OptimizeStops     bool        `json:"optimizeStops,omitempty"`
+	Emissions         float64     `json:"emissions"`
+	MaxDetourDistance int         `json:"maxDetourDistance,omitempty"`
+	MaxDetourDuration int         `json:"maxDetourDuration,omitempty"`
+	Proposals         []*Proposal `json:"proposals,omitempty"`
+
+	// RouteIndex is the index of the route taken by the driver among the
+	// ones suggested by the routing provider, listed in Routes when the trip
+	// is created or quoted. RouteSummary is the summary of that route, which
+	// the driver gives back from the quote to make sure that the suggestions
+	// did not change in between.
+	RouteIndex   int            `json:"routeIndex,omitempty"`
+	RouteSummary string         `json:"routeSummary,omitempty"`
+	Routes       []*RouteOption `json:"routes,omitempty"`
+
+	// RouteProvider is the name of the routing provider that generated the
+	// trip's route.
+	RouteProvider string `json:"routeProvider,omitempty"`
+
+	Match *Match `json:"match,omitempty"`
 }
 
 const (
@@ -73,6 +104,25 @@ func (t *Trip) Validate() error {
 		return ValidationError{fmt.Sprintf("totalDistance must be greater %f", MinimumTotalDistance)}
 	}
 
+	if t.MaxDetourDistance < 0 {
+		return ValidationError{"maxDetourDistance must be greater than 0"}
+	}
+
+	if t.MaxDetourDuration < 0 {
+		return ValidationError{"maxDetourDuration must be greater than 0"}
+	}
+
+	if t.RouteIndex < 0 {
+		return ValidationError{"routeIndex must be at least 0"}
+	}
+
+	if t.Costs != nil {
+		err := t.Costs.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
 	if t.Details != nil {
 		err := t.Details.Validate()
 		if err != nil {
@@ -113,4 +163,43 @@ func (t *Trip) UpdateReservationCount(seats int) {
 	} else {
 		t.PricePerSeat = t.TotalTripPrice / float64(t.ReservationsCount)
 	}
+
+	if t.PriceBreakdown != nil {
+		t.PricePerSeatBreakdown = t.PriceBreakdown.split(t.ReservationsCount)
+	}
+}
+
+// FareFor returns the price a passenger would pay to reserve the given number
+// of seats, once the total price is split with the seats already reserved.
+func (t *Trip) FareFor(seats int) float64 {
+	if seats <= 0 {
+		return 0
+	}
+
+	return t.TotalTripPrice / float64(t.ReservationsCount+seats) * float64(seats)
+}
+
+// IndexOfStop returns the position of the stop with the given ID in the trip's
+// stops, or -1 if the trip has no such stop.
+func (t *Trip) IndexOfStop(ID ID) int {
+	for i, s := range t.Stops {
+		if s.ID == ID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// AvailableSeats returns the number of seats that are available during the
+// whole segment between the stops at the given positions.
+func (t *Trip) AvailableSeats(from, to int) int {
+	seats := t.Seats
+	for i := from; i < to && i < len(t.Stops); i++ {
+		if t.Stops[i].Seats < seats {
+			seats = t.Stops[i].Seats
+		}
+	}
+
+	return seats
 }
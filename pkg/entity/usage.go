@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// A RouteUsage records a call made to a routing provider, to keep track of
+// the usage of its API.
+type RouteUsage struct {
+	Provider string `json:"provider"`
+
+	// UserID represents the user to whom the call is charged, which is the
+	// authenticated user who made the request or, for requests made by
+	// other services, the trip's driver.
+	UserID ID `json:"userId"`
+
+	RequestID string `json:"requestId"`
+
+	// Elements represents the number of legs the provider was asked to
+	// route, which is what its usage is billed on.
+	Elements int `json:"elements"`
+
+	// Latency represents the time the provider took to respond, in
+	// milliseconds.
+	Latency int64 `json:"latency"`
+
+	Outcome   string    `json:"outcome"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+const (
+	// RouteUsageOutcomeSuccess represents a call for which the provider
+	// returned a route.
+	RouteUsageOutcomeSuccess = "success"
+
+	// RouteUsageOutcomeFailure represents a call for which the provider
+	// failed to return a route.
+	RouteUsageOutcomeFailure = "failure"
+)
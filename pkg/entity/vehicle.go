@@ -7,25 +7,114 @@ import (
 
 // Vehicle contains a vehicle's information.
 type Vehicle struct {
-	ID    ID     `json:"id"`
-	Make  string `json:"make"`
-	Year  int    `json:"year"`
-	Model string `json:"model"`
+	ID       ID       `json:"id"`
+	Make     string   `json:"make"`
+	Year     int      `json:"year"`
+	Model    string   `json:"model"`
+	Features []string `json:"features"`
+
+	// Class represents the size of the vehicle, which can affect the price
+	// of its trips.
+	Class string `json:"class,omitempty"`
+
+	// Consumption represents the vehicle's fuel consumption in liters per
+	// 100 kilometers, or in kWh per 100 kilometers if it is electric.
+	Consumption float64 `json:"consumption,omitempty"`
+
+	// FuelType represents what the vehicle runs on, which determines its
+	// emissions.
+	FuelType string `json:"fuelType,omitempty"`
 }
 
 const (
 	// YearMinimum represents the minimum year of a car.
 	YearMinimum = 1900
+
+	// VehicleFeatureElectric represents an electric vehicle.
+	VehicleFeatureElectric = "electric"
+
+	// VehicleFeatureAirConditioning represents a vehicle with air
+	// conditioning.
+	VehicleFeatureAirConditioning = "airConditioning"
+
+	// VehicleFeatureWinterTires represents a vehicle equipped with winter
+	// tires.
+	VehicleFeatureWinterTires = "winterTires"
+
+	// VehicleFeatureBikeRack represents a vehicle with a bike rack.
+	VehicleFeatureBikeRack = "bikeRack"
+
+	// VehicleFeatureSkiRack represents a vehicle with a ski rack.
+	VehicleFeatureSkiRack = "skiRack"
+
+	// VehicleClassCompact represents a compact car.
+	VehicleClassCompact = "compact"
+
+	// VehicleClassMidsize represents a mid-size car.
+	VehicleClassMidsize = "midsize"
+
+	// VehicleClassFullsize represents a full-size car.
+	VehicleClassFullsize = "fullsize"
+
+	// VehicleClassSUV represents a sport utility vehicle.
+	VehicleClassSUV = "suv"
+
+	// VehicleClassMinivan represents a minivan.
+	VehicleClassMinivan = "minivan"
+
+	// MinimumConsumption represents the minimum fuel consumption of a vehicle
+	// in liters per 100 kilometers.
+	MinimumConsumption = 0.0
 )
 
+// IsVehicleFeature checks whether the given feature is a known vehicle
+// feature.
+func IsVehicleFeature(feature string) bool {
+	switch feature {
+	case VehicleFeatureElectric,
+		VehicleFeatureAirConditioning,
+		VehicleFeatureWinterTires,
+		VehicleFeatureBikeRack,
+		VehicleFeatureSkiRack:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsVehicleClass checks whether the given class is a known vehicle class.
+func IsVehicleClass(class string) bool {
+	switch class {
+	case VehicleClassCompact,
+		VehicleClassMidsize,
+		VehicleClassFullsize,
+		VehicleClassSUV,
+		VehicleClassMinivan:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasFeature checks whether the vehicle has the given feature.
+func (v *Vehicle) HasFeature(feature string) bool {
+	for _, f := range v.Features {
+		if f == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Validate validates that the vehicles's required fields are filled out correctly.
 func (v *Vehicle) Validate() error {
 	if v.ID.IsZero() {
 		return ValidationError{fmt.Sprintf("id must not be nil")}
 	}
 
-	if v.Year <= YearMinimum && v.Year > time.Now().Year() {
-		return ValidationError{fmt.Sprintf("year must me between %d and %d", YearMinimum, time.Now().Year())}
+	if v.Year <= YearMinimum || v.Year > time.Now().Year() {
+		return ValidationError{fmt.Sprintf("year must be between %d and %d", YearMinimum, time.Now().Year())}
 	}
 
 	if v.Make == "" {
@@ -36,5 +125,29 @@ func (v *Vehicle) Validate() error {
 		return ValidationError{"model is missing"}
 	}
 
+	if v.Class != "" && !IsVehicleClass(v.Class) {
+		return ValidationError{fmt.Sprintf("unknown vehicle class \"%s\"", v.Class)}
+	}
+
+	if v.FuelType != "" && !IsVehicleFuel(v.FuelType) {
+		return ValidationError{fmt.Sprintf("unknown vehicle fuel type \"%s\"", v.FuelType)}
+	}
+
+	if v.Consumption < MinimumConsumption {
+		return ValidationError{fmt.Sprintf("consumption must be greater than %f", MinimumConsumption)}
+	}
+
+	for i, f := range v.Features {
+		if !IsVehicleFeature(f) {
+			return ValidationError{fmt.Sprintf("unknown vehicle feature \"%s\"", f)}
+		}
+
+		for _, other := range v.Features[:i] {
+			if f == other {
+				return ValidationError{fmt.Sprintf("vehicle feature \"%s\" is duplicated", f)}
+			}
+		}
+	}
+
 	return nil
 }
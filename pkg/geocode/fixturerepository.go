@@ -0,0 +1,80 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// A FixtureRepository is a repository that finds locations in a fixed set of
+// addresses and place IDs, without an external geocoding provider. It makes
+// it possible to create trips from addresses in development and tests.
+type FixtureRepository struct {
+	fixtures map[string][]*entity.Point
+}
+
+// NewFixtureRepository creates a repository that finds locations in the given
+// fixtures, which map addresses and place IDs to the locations they match.
+// Addresses are matched regardless of case and surrounding spaces.
+func NewFixtureRepository(fixtures map[string][]*entity.Point) (Repository, error) {
+	if fixtures == nil {
+		return nil, fmt.Errorf("geocode.FixtureRepository: fixtures are nil")
+	}
+
+	normalized := make(map[string][]*entity.Point, len(fixtures))
+	for query, points := range fixtures {
+		normalized[normalizeQuery(query)] = points
+	}
+
+	return &FixtureRepository{normalized}, nil
+}
+
+// NewFixtureRepositoryFromFile creates a repository that finds locations in
+// the fixtures contained in a JSON file, which maps addresses and place IDs to
+// arrays of points.
+func NewFixtureRepositoryFromFile(name string) (Repository, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("geocode.FixtureRepository: failed to open fixtures (%s)", err)
+	}
+	defer f.Close()
+
+	var fixtures map[string][]*entity.Point
+	err = json.NewDecoder(f).Decode(&fixtures)
+	if err != nil {
+		return nil, fmt.Errorf("geocode.FixtureRepository: failed to decode fixtures (%s)", err)
+	}
+
+	return NewFixtureRepository(fixtures)
+}
+
+// Geocode returns the locations matching the point's place ID or, if it has
+// none, its address.
+func (fr *FixtureRepository) Geocode(ctx context.Context, p *entity.Point) ([]*entity.Point, error) {
+	query := p.PlaceID
+	if query == "" {
+		query = p.Address
+	}
+
+	if query == "" {
+		return nil, fmt.Errorf("geocode.FixtureRepository: address OR placeId must be specified")
+	}
+
+	fixtures := fr.fixtures[normalizeQuery(query)]
+
+	points := make([]*entity.Point, len(fixtures))
+	for i, f := range fixtures {
+		point := *f
+		points[i] = &point
+	}
+
+	return points, nil
+}
+
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
@@ -0,0 +1,69 @@
+package geocode
+
+import (
+	"context"
+	"fmt"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"googlemaps.github.io/maps"
+)
+
+// A GoogleMapsRepository is a repository that finds locations using the
+// Google Maps Geocoding API.
+type GoogleMapsRepository struct {
+	client *maps.Client
+}
+
+// NewGoogleMapsRepository creates a repository that makes requests to the
+// Google Maps Geocoding API.
+func NewGoogleMapsRepository(client *maps.Client) (Repository, error) {
+	if client == nil {
+		return nil, fmt.Errorf("geocode.GoogleMapsRepository: client is nil")
+	}
+
+	return &GoogleMapsRepository{client}, nil
+}
+
+// Geocode asks the Google Maps Geocoding API for the locations matching the
+// point's place ID or, if it has none, its address.
+func (gr *GoogleMapsRepository) Geocode(ctx context.Context, p *entity.Point) ([]*entity.Point, error) {
+	var results []maps.GeocodingResult
+	var err error
+	if p.PlaceID != "" {
+		results, err = gr.client.ReverseGeocode(ctx, &maps.GeocodingRequest{PlaceID: p.PlaceID})
+	} else if p.Address != "" {
+		results, err = gr.client.Geocode(ctx, &maps.GeocodingRequest{Address: p.Address})
+	} else {
+		return nil, fmt.Errorf("geocode.GoogleMapsRepository: address OR placeId must be specified")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("geocode.GoogleMapsRepository: error geocoding, %s", err)
+	}
+
+	points := make([]*entity.Point, 0, len(results))
+	for _, r := range results {
+		if containsPlace(points, r.PlaceID) {
+			continue
+		}
+
+		points = append(points, &entity.Point{
+			Latitude:  r.Geometry.Location.Lat,
+			Longitude: r.Geometry.Location.Lng,
+			Address:   r.FormattedAddress,
+			PlaceID:   r.PlaceID,
+		})
+	}
+
+	return points, nil
+}
+
+func containsPlace(points []*entity.Point, placeID string) bool {
+	for _, p := range points {
+		if p.PlaceID == placeID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,13 @@
+package geocode
+
+import (
+	"context"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// Repository is an interface representing the ability to find the locations
+// matching an address or a place ID.
+type Repository interface {
+	Geocode(ctx context.Context, p *entity.Point) ([]*entity.Point, error)
+}
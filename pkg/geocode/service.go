@@ -0,0 +1,53 @@
+package geocode
+
+import (
+	"context"
+	"fmt"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// UseCase is an interface representing the ability to resolve the locations
+// of a trip's stops.
+type UseCase interface {
+	ResolveStops(ctx context.Context, t *entity.Trip) error
+}
+
+// A Service resolves the stops given as addresses or place IDs into
+// coordinates.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a geocoding service that finds locations through a
+// repository.
+func NewService(repo Repository) UseCase {
+	return &Service{repo}
+}
+
+// ResolveStops updates the points of a trip's stops that are given as an
+// address or a place ID with the coordinates of the only location matching
+// them. A validation error is returned if none or several locations match.
+func (s *Service) ResolveStops(ctx context.Context, t *entity.Trip) error {
+	if t == nil {
+		return fmt.Errorf("geocode.Service: trip is nil")
+	}
+
+	for _, stop := range t.Stops {
+		if stop == nil || stop.Point == nil || !stop.Point.NeedsGeocoding() {
+			continue
+		}
+
+		candidates, err := s.repo.Geocode(ctx, stop.Point)
+		if err != nil {
+			return err
+		}
+
+		err = stop.Point.Resolve(candidates)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package itinerary
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/reservation"
+	"azure.com/ecovo/trip-service/pkg/trip"
+)
+
+// UseCase is an interface representing the ability to handle the business
+// logic that involves itineraries.
+type UseCase interface {
+	Find(filters *entity.ItineraryFilters) ([]*entity.Itinerary, error)
+	Reserve(r *entity.ItineraryReservation) error
+}
+
+// A Service handles the business logic related to itineraries made of
+// multiple trips.
+type Service struct {
+	tripService        trip.UseCase
+	reservationService reservation.UseCase
+}
+
+// NewService creates an itinerary service to combine trips and reserve them.
+func NewService(tripService trip.UseCase, reservationService reservation.UseCase) *Service {
+	return &Service{tripService, reservationService}
+}
+
+// Find combines two trips that share nearby stops to get from the filters'
+// source to their destination, with a transfer wait within the filters'
+// range. The itineraries are sorted from shortest to longest.
+func (s *Service) Find(filters *entity.ItineraryFilters) ([]*entity.Itinerary, error) {
+	if filters == nil {
+		return nil, fmt.Errorf("itinerary.Service: filters are nil")
+	}
+
+	err := filters.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	firstLegFilters := filters.Filters
+	firstLegFilters.DestinationLatitude = nil
+	firstLegFilters.DestinationLongitude = nil
+
+	firstLegs, err := s.tripService.Find(&firstLegFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	secondLegFilters := filters.Filters
+	secondLegFilters.SourceLatitude = nil
+	secondLegFilters.SourceLongitude = nil
+
+	secondLegs, err := s.tripService.Find(&secondLegFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := entity.MinimumSeats
+	if filters.Seats != nil && *filters.Seats > seats {
+		seats = *filters.Seats
+	}
+
+	itineraries := make([]*entity.Itinerary, 0)
+	for _, first := range firstLegs {
+		for _, second := range secondLegs {
+			if first.ID == second.ID {
+				continue
+			}
+
+			i := connect(first, second, filters, seats)
+			if i != nil {
+				itineraries = append(itineraries, i)
+			}
+		}
+	}
+
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		if itineraries[i].Duration == itineraries[j].Duration {
+			return itineraries[i].Price < itineraries[j].Price
+		}
+		return itineraries[i].Duration < itineraries[j].Duration
+	})
+
+	return itineraries, nil
+}
+
+// connect finds the best place to transfer from the first trip to the second
+// one, which is the one that gets the passenger to their destination the
+// fastest. If the trips cannot be connected, nil is returned.
+func connect(first, second *entity.Trip, filters *entity.ItineraryFilters, seats int) *entity.Itinerary {
+	pickup := first.IndexOfStop(first.Match.PickupID)
+	dropOff := second.IndexOfStop(second.Match.DropOffID)
+	if pickup < 0 || dropOff < 0 {
+		return nil
+	}
+
+	waitRange := filters.TransferWaitRange()
+	radius := filters.TransferRadius()
+
+	var best *entity.Itinerary
+	for i := pickup + 1; i < len(first.Stops); i++ {
+		if first.AvailableSeats(pickup, i) < seats {
+			break
+		}
+
+		for j := dropOff - 1; j >= 0; j-- {
+			if second.AvailableSeats(j, dropOff) < seats {
+				break
+			}
+
+			transferDistance := first.Stops[i].Point.DistanceTo(second.Stops[j].Point)
+			if transferDistance > radius {
+				continue
+			}
+
+			transferWait := second.Stops[j].Departure().Sub(first.Stops[i].Arrival())
+			if !waitRange.Contains(transferWait) {
+				continue
+			}
+
+			leaveAt := first.Stops[pickup].Departure()
+			arriveBy := second.Stops[dropOff].Arrival()
+			duration := int(arriveBy.Sub(leaveAt).Seconds())
+			if best != nil && duration >= best.Duration {
+				continue
+			}
+
+			firstFare := first.FareFor(seats)
+			secondFare := second.FareFor(seats)
+			best = &entity.Itinerary{
+				Legs: []*entity.ItineraryLeg{
+					{Trip: first, Pickup: first.Stops[pickup], DropOff: first.Stops[i], Fare: firstFare},
+					{Trip: second, Pickup: second.Stops[j], DropOff: second.Stops[dropOff], Fare: secondFare},
+				},
+				Price:            firstFare + secondFare,
+				LeaveAt:          leaveAt,
+				ArriveBy:         arriveBy,
+				Duration:         duration,
+				TransferWait:     int(transferWait.Seconds()),
+				TransferDistance: transferDistance,
+			}
+		}
+	}
+
+	return best
+}
+
+// Reserve makes the reservation of every leg of an itinerary. If any of them
+// fails, the reservations that were already made are cancelled, so that the
+// passenger is never left with only part of their itinerary.
+func (s *Service) Reserve(r *entity.ItineraryReservation) error {
+	if r == nil {
+		return fmt.Errorf("itinerary.Service: reservation is nil")
+	}
+
+	err := r.Validate()
+	if err != nil {
+		return err
+	}
+
+	for i, res := range r.Reservations {
+		err := s.reservationService.Register(res)
+		if err != nil {
+			s.cancel(r.Reservations[:i])
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) cancel(reservations []*entity.Reservation) {
+	for _, res := range reservations {
+		err := s.reservationService.Delete(res)
+		if err != nil {
+			log.Printf("itinerary.Service: failed to cancel reservation on trip \"%s\" (%s)", res.TripID, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// UseCase is an interface representing the ability to price trips.
+type UseCase interface {
+	PriceTrip(t *entity.Trip) error
+}
+
+// A Service prices trips with a strategy, then applies the minimum fare and
+// the rounding rules.
+type Service struct {
+	strategy Strategy
+	conf     *Config
+}
+
+// Config contains the rules applied to every price, whatever the strategy.
+type Config struct {
+	// MinimumFare specifies the minimum total price of a trip.
+	MinimumFare float64
+
+	// RoundingIncrement specifies the increment (in dollars) prices are
+	// rounded to, or 0 to keep them as is.
+	RoundingIncrement float64
+
+	// RoundingMode specifies how prices are rounded to the increment.
+	RoundingMode string
+}
+
+const (
+	// RoundingModeNearest rounds prices to the nearest increment.
+	RoundingModeNearest = "nearest"
+
+	// RoundingModeUp rounds prices up to the next increment.
+	RoundingModeUp = "up"
+
+	// RoundingModeDown rounds prices down to the previous increment.
+	RoundingModeDown = "down"
+)
+
+// NewService creates a pricing service that prices trips with the given
+// strategy.
+func NewService(strategy Strategy, conf *Config) (UseCase, error) {
+	if strategy == nil {
+		return nil, fmt.Errorf("pricing.Service: strategy is nil")
+	}
+
+	if conf == nil {
+		return nil, fmt.Errorf("pricing.Service: missing configuration")
+	}
+
+	if conf.MinimumFare < 0 {
+		return nil, fmt.Errorf("pricing.Service: minimum fare must be at least 0")
+	}
+
+	if conf.RoundingIncrement < 0 {
+		return nil, fmt.Errorf("pricing.Service: rounding increment must be at least 0")
+	}
+
+	switch conf.RoundingMode {
+	case "":
+		conf.RoundingMode = RoundingModeNearest
+	case RoundingModeNearest, RoundingModeUp, RoundingModeDown:
+	default:
+		return nil, fmt.Errorf("pricing.Service: unknown rounding mode \"%s\"", conf.RoundingMode)
+	}
+
+	return &Service{strategy, conf}, nil
+}
+
+// PriceTrip updates the total price of a trip that has been routed, which is
+// the price computed from its distance plus the costs declared by its driver.
+// Only the former is subject to the minimum fare and rounding.
+func (s *Service) PriceTrip(t *entity.Trip) error {
+	if t == nil {
+		return fmt.Errorf("pricing.Service: trip is nil")
+	}
+
+	price, err := s.strategy.Price(t)
+	if err != nil {
+		return err
+	}
+
+	t.SetPrice(s.round(math.Max(price, s.conf.MinimumFare)))
+
+	return nil
+}
+
+func (s *Service) round(price float64) float64 {
+	if s.conf.RoundingIncrement == 0 {
+		return price
+	}
+
+	increments := price / s.conf.RoundingIncrement
+	switch s.conf.RoundingMode {
+	case RoundingModeUp:
+		increments = math.Ceil(increments)
+	case RoundingModeDown:
+		increments = math.Floor(increments)
+	default:
+		increments = math.Round(increments)
+	}
+
+	return increments * s.conf.RoundingIncrement
+}
@@ -0,0 +1,209 @@
+package pricing
+
+import (
+	"fmt"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// A Strategy computes the total price of a trip once it has been routed.
+type Strategy interface {
+	Price(t *entity.Trip) (float64, error)
+}
+
+const (
+	// PricePerKilometer represents the price (in dollars) established by the Canadian
+	// government as the maximum allocation for vehicle usage.
+	// https://www.canada.ca/fr/agence-revenu/services/impot/entreprises/sujets/retenues-paie/avantages-allocations/automobile/allocations-frais-automobile-vehicule-a-moteur/taux-allocations-frais-automobile.html
+	PricePerKilometer = 0.58
+
+	// DefaultFuelPrice represents the price (in dollars) of a liter of fuel
+	// when none is configured.
+	DefaultFuelPrice = 1.30
+
+	// DefaultElectricityPrice represents the price (in dollars) of a kWh of
+	// electricity when none is configured.
+	DefaultElectricityPrice = 0.10
+
+	// DefaultConsumption represents the fuel consumption (in liters per 100
+	// kilometers) of vehicles that do not specify theirs.
+	DefaultConsumption = 8.0
+)
+
+// kilometers returns a trip's total distance in kilometers.
+func kilometers(t *entity.Trip) float64 {
+	return float64(t.TotalDistance) / 1000
+}
+
+// A PerKilometerStrategy prices trips at a fixed rate per kilometer.
+type PerKilometerStrategy struct {
+	rate float64
+}
+
+// NewPerKilometerStrategy creates a strategy that prices trips at the given
+// rate (in dollars) per kilometer.
+func NewPerKilometerStrategy(rate float64) (Strategy, error) {
+	if rate < 0 {
+		return nil, fmt.Errorf("pricing.PerKilometerStrategy: rate must be at least 0")
+	}
+
+	return &PerKilometerStrategy{rate}, nil
+}
+
+// Price returns the trip's distance multiplied by the rate.
+func (s *PerKilometerStrategy) Price(t *entity.Trip) (float64, error) {
+	return kilometers(t) * s.rate, nil
+}
+
+// A Region is an area, delimited by coordinates, with its own rate per
+// kilometer.
+type Region struct {
+	Name         string  `json:"name"`
+	MinLatitude  float64 `json:"minLatitude"`
+	MaxLatitude  float64 `json:"maxLatitude"`
+	MinLongitude float64 `json:"minLongitude"`
+	MaxLongitude float64 `json:"maxLongitude"`
+	Rate         float64 `json:"rate"`
+}
+
+// Contains checks whether the point is within the region.
+func (r *Region) Contains(p *entity.Point) bool {
+	return p.Latitude >= r.MinLatitude && p.Latitude <= r.MaxLatitude &&
+		p.Longitude >= r.MinLongitude && p.Longitude <= r.MaxLongitude
+}
+
+// A RegionStrategy prices trips at the rate per kilometer of the region they
+// leave from.
+type RegionStrategy struct {
+	regions     []*Region
+	defaultRate float64
+}
+
+// NewRegionStrategy creates a strategy that prices trips at the rate of the
+// first region containing their first stop, or at the default rate if none
+// does.
+func NewRegionStrategy(regions []*Region, defaultRate float64) (Strategy, error) {
+	if defaultRate < 0 {
+		return nil, fmt.Errorf("pricing.RegionStrategy: default rate must be at least 0")
+	}
+
+	for i, r := range regions {
+		if r == nil {
+			return nil, fmt.Errorf("pricing.RegionStrategy: region %d is nil", i)
+		}
+
+		if r.Rate < 0 {
+			return nil, fmt.Errorf("pricing.RegionStrategy: rate of region \"%s\" must be at least 0", r.Name)
+		}
+
+		if r.MinLatitude > r.MaxLatitude || r.MinLongitude > r.MaxLongitude {
+			return nil, fmt.Errorf("pricing.RegionStrategy: bounds of region \"%s\" are invalid", r.Name)
+		}
+	}
+
+	return &RegionStrategy{regions, defaultRate}, nil
+}
+
+// Price returns the trip's distance multiplied by the rate of the region it
+// leaves from.
+func (s *RegionStrategy) Price(t *entity.Trip) (float64, error) {
+	if len(t.Stops) == 0 || t.Stops[0].Point == nil {
+		return 0, fmt.Errorf("pricing.RegionStrategy: trip has no origin")
+	}
+
+	rate := s.defaultRate
+	for _, r := range s.regions {
+		if r.Contains(t.Stops[0].Point) {
+			rate = r.Rate
+			break
+		}
+	}
+
+	return kilometers(t) * rate, nil
+}
+
+// A VehicleClassStrategy prices trips at a rate per kilometer that depends on
+// the class of the vehicle.
+type VehicleClassStrategy struct {
+	rates       map[string]float64
+	defaultRate float64
+}
+
+// NewVehicleClassStrategy creates a strategy that prices trips at the rate of
+// their vehicle's class, or at the default rate if the class has none.
+func NewVehicleClassStrategy(rates map[string]float64, defaultRate float64) (Strategy, error) {
+	if defaultRate < 0 {
+		return nil, fmt.Errorf("pricing.VehicleClassStrategy: default rate must be at least 0")
+	}
+
+	for class, rate := range rates {
+		if !entity.IsVehicleClass(class) {
+			return nil, fmt.Errorf("pricing.VehicleClassStrategy: unknown vehicle class \"%s\"", class)
+		}
+
+		if rate < 0 {
+			return nil, fmt.Errorf("pricing.VehicleClassStrategy: rate of class \"%s\" must be at least 0", class)
+		}
+	}
+
+	return &VehicleClassStrategy{rates, defaultRate}, nil
+}
+
+// Price returns the trip's distance multiplied by the rate of its vehicle's
+// class.
+func (s *VehicleClassStrategy) Price(t *entity.Trip) (float64, error) {
+	rate := s.defaultRate
+	if t.Vehicle != nil {
+		if r, ok := s.rates[t.Vehicle.Class]; ok {
+			rate = r
+		}
+	}
+
+	return kilometers(t) * rate, nil
+}
+
+// A FuelConsumptionStrategy prices trips at the cost of the fuel, or the
+// electricity, their vehicle consumes.
+type FuelConsumptionStrategy struct {
+	fuelPrice          float64
+	electricityPrice   float64
+	defaultConsumption float64
+}
+
+// NewFuelConsumptionStrategy creates a strategy that prices trips from their
+// vehicle's consumption and the price of fuel, or of electricity for electric
+// vehicles. Vehicles that do not specify their consumption are assumed to
+// consume the default one, or the default one of electric vehicles.
+func NewFuelConsumptionStrategy(fuelPrice float64, electricityPrice float64, defaultConsumption float64) (Strategy, error) {
+	if fuelPrice < 0 {
+		return nil, fmt.Errorf("pricing.FuelConsumptionStrategy: fuel price must be at least 0")
+	}
+
+	if electricityPrice < 0 {
+		return nil, fmt.Errorf("pricing.FuelConsumptionStrategy: electricity price must be at least 0")
+	}
+
+	if defaultConsumption <= 0 {
+		return nil, fmt.Errorf("pricing.FuelConsumptionStrategy: default consumption must be greater than 0")
+	}
+
+	return &FuelConsumptionStrategy{fuelPrice, electricityPrice, defaultConsumption}, nil
+}
+
+// Price returns the cost of the fuel, or of the electricity for an electric
+// vehicle, consumed to drive the trip's distance. The consumption of electric
+// vehicles is in kWh per 100 kilometers.
+func (s *FuelConsumptionStrategy) Price(t *entity.Trip) (float64, error) {
+	consumption := s.defaultConsumption
+	price := s.fuelPrice
+	if t.Vehicle != nil && t.Vehicle.Fuel() == entity.VehicleFuelElectric {
+		consumption = entity.DefaultConsumption(entity.VehicleFuelElectric)
+		price = s.electricityPrice
+	}
+
+	if t.Vehicle != nil && t.Vehicle.Consumption > 0 {
+		consumption = t.Vehicle.Consumption
+	}
+
+	return kilometers(t) / 100 * consumption * price, nil
+}
@@ -1,23 +1,36 @@
 package pubsub
 
-import "azure.com/ecovo/trip-service/pkg/pubsub/subscription"
+import (
+	"sync"
+
+	"azure.com/ecovo/trip-service/pkg/pubsub/subscription"
+)
 
 // UseCase is an interface representing the ability to handle the business
 // logic that involves subscribing and unsubscribing to topics.
 type UseCase interface {
 	Subscribe(topic string) (subscription.Subscription, error)
 	Unsubscribe(topic string)
+	Publish(topic string, msg *subscription.Message) error
 }
 
 // A Service handles the business logic related to subscriptions.
 type Service struct {
 	repo subscription.Repository
+
+	// publishers caches the subscriptions used to publish on a topic, so
+	// they are only created once.
+	mu         sync.Mutex
+	publishers map[string]subscription.Subscription
 }
 
 // NewService creates a pubsub service to handler business logic related to
 // subscriptions.
 func NewService(repo subscription.Repository) UseCase {
-	return &Service{repo}
+	return &Service{
+		repo:       repo,
+		publishers: make(map[string]subscription.Subscription),
+	}
 }
 
 // Subscribe creates a subscription to the given topic.
@@ -29,3 +42,37 @@ func (s *Service) Subscribe(topic string) (subscription.Subscription, error) {
 func (s *Service) Unsubscribe(topic string) {
 	s.repo.Delete(topic)
 }
+
+// Publish sends a single message on the given topic. The subscription used to
+// publish is kept and reused for the following messages on the same topic.
+func (s *Service) Publish(topic string, msg *subscription.Message) error {
+	sub, err := s.publisher(topic)
+	if err != nil {
+		return err
+	}
+
+	return sub.Publish(msg)
+}
+
+func (s *Service) publisher(topic string) (subscription.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.publishers[topic]; ok {
+		return sub, nil
+	}
+
+	sub, err := s.repo.Create(topic)
+	if err != nil {
+		return nil, err
+	}
+	s.publishers[topic] = sub
+
+	return sub, nil
+}
+
+// UserTopic returns the topic on which the user with the given ID receives
+// notifications.
+func UserTopic(userID string) string {
+	return "users:" + userID
+}
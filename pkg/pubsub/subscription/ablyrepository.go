@@ -3,15 +3,17 @@ package subscription
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/ably/ably-go/ably"
 )
 
 // An AblyRepository is a repository that performs CRUD operations on
 // subscriptions in an in-memory database. It manages subscriptions to Ably
-// REST channels.
+// REST channels. It is safe for concurrent use.
 type AblyRepository struct {
 	client              *ably.RestClient
+	mu                  sync.Mutex
 	subcriptionsByTopic map[string][]Subscription
 }
 
@@ -59,6 +61,9 @@ func (r *AblyRepository) addSubscriptionToTopic(sub Subscription) error {
 
 	topic := sub.Topic()
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	subs, ok := r.subcriptionsByTopic[topic]
 	if !ok {
 		subs = make([]Subscription, 0, 1)
@@ -74,6 +79,9 @@ func (r *AblyRepository) removeSubscriptionFromTopic(topic string) {
 		return
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	subs, ok := r.subcriptionsByTopic[topic]
 	if !ok {
 		return
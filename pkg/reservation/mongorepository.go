@@ -0,0 +1,224 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// A MongoRepository is a repository that performs CRUD operations on
+// reservations in a MongoDB collection.
+type MongoRepository struct {
+	collection *mongo.Collection
+}
+
+type document struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	TripID         primitive.ObjectID `bson:"tripId"`
+	UserID         primitive.ObjectID `bson:"userId"`
+	SourceID       primitive.ObjectID `bson:"sourceId"`
+	DestinationID  primitive.ObjectID `bson:"destinationId"`
+	Seats          int                `bson:"seats"`
+	Distance       int                `bson:"distance"`
+	Emissions      float64            `bson:"emissions"`
+	EmissionsSaved float64            `bson:"emissionsSaved"`
+}
+
+type emissionsDocument struct {
+	Reservations   int     `bson:"reservations"`
+	Distance       int     `bson:"distance"`
+	Emissions      float64 `bson:"emissions"`
+	EmissionsSaved float64 `bson:"emissionsSaved"`
+}
+
+func (d document) Entity() *entity.Reservation {
+	return &entity.Reservation{
+		entity.NewIDFromHex(d.ID.Hex()),
+		entity.NewIDFromHex(d.TripID.Hex()),
+		entity.NewIDFromHex(d.UserID.Hex()),
+		entity.NewIDFromHex(d.SourceID.Hex()),
+		entity.NewIDFromHex(d.DestinationID.Hex()),
+		d.Seats,
+		d.Distance,
+		d.Emissions,
+		d.EmissionsSaved,
+	}
+}
+
+func newDocumentFromEntity(r *entity.Reservation) (*document, error) {
+	if r == nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: entity is nil")
+	}
+
+	IDs := make([]primitive.ObjectID, 5)
+	for i, rawID := range []entity.ID{r.ID, r.TripID, r.UserID, r.SourceID, r.DestinationID} {
+		ID, err := getObjectID(rawID)
+		if err != nil {
+			return nil, err
+		}
+		IDs[i] = ID
+	}
+
+	return &document{
+		IDs[0],
+		IDs[1],
+		IDs[2],
+		IDs[3],
+		IDs[4],
+		r.Seats,
+		r.Distance,
+		r.Emissions,
+		r.EmissionsSaved,
+	}, nil
+}
+
+// NewMongoRepository creates a reservation repository for a MongoDB
+// collection.
+func NewMongoRepository(collection *mongo.Collection) (Repository, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: collection is nil")
+	}
+
+	return &MongoRepository{collection}, nil
+}
+
+// FindByTripID retrieves the reservations made on the trip with the given ID.
+func (r *MongoRepository) FindByTripID(tripID entity.ID) ([]*entity.Reservation, error) {
+	objectID, err := primitive.ObjectIDFromHex(tripID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: failed to create object ID")
+	}
+
+	cur, err := r.collection.Find(context.TODO(), bson.D{{"tripId", objectID}})
+	if err != nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: no reservation found (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	reservations := make([]*entity.Reservation, 0)
+	for cur.Next(context.TODO()) {
+		var d document
+		err := cur.Decode(&d)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, d.Entity())
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// Create stores the new reservation in the database and returns the unique
+// identifier that was generated for it.
+func (r *MongoRepository) Create(res *entity.Reservation) (entity.ID, error) {
+	if res == nil {
+		return entity.NilID, fmt.Errorf("reservation.MongoRepository: failed to create reservation (reservation is nil)")
+	}
+
+	d, err := newDocumentFromEntity(res)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("reservation.MongoRepository: failed to create reservation document from entity (%s)", err)
+	}
+
+	result, err := r.collection.InsertOne(context.TODO(), d)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("reservation.MongoRepository: failed to create reservation (%s)", err)
+	}
+
+	ID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return entity.NilID, fmt.Errorf("reservation.MongoRepository: failed to get ID of created reservation")
+	}
+
+	return entity.ID(ID.Hex()), nil
+}
+
+// Delete removes a reservation matching the given one's trip, user, source,
+// destination and seats from the database.
+func (r *MongoRepository) Delete(res *entity.Reservation) error {
+	d, err := newDocumentFromEntity(res)
+	if err != nil {
+		return fmt.Errorf("reservation.MongoRepository: failed to create reservation document from entity (%s)", err)
+	}
+
+	filter := bson.D{
+		{"tripId", d.TripID},
+		{"userId", d.UserID},
+		{"sourceId", d.SourceID},
+		{"destinationId", d.DestinationID},
+		{"seats", d.Seats},
+	}
+	_, err = r.collection.DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("reservation.MongoRepository: failed to delete reservation on trip \"%s\" (%s)", res.TripID, err)
+	}
+
+	return nil
+}
+
+// Emissions sums the distance, the emissions and the emissions saved of the
+// reservations made by the user with the given ID.
+func (r *MongoRepository) Emissions(userID entity.ID) (*entity.EmissionsSummary, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: failed to create object ID")
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"userId": objectID}},
+		bson.M{"$group": bson.M{
+			"_id":            nil,
+			"reservations":   bson.M{"$sum": 1},
+			"distance":       bson.M{"$sum": "$distance"},
+			"emissions":      bson.M{"$sum": "$emissions"},
+			"emissionsSaved": bson.M{"$sum": "$emissionsSaved"},
+		}},
+	}
+
+	cur, err := r.collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: failed to compute emissions (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	var d emissionsDocument
+	if cur.Next(context.TODO()) {
+		err = cur.Decode(&d)
+		if err != nil {
+			return nil, fmt.Errorf("reservation.MongoRepository: failed to decode emissions (%s)", err)
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return &entity.EmissionsSummary{
+		userID,
+		d.Reservations,
+		d.Distance,
+		d.Emissions,
+		d.EmissionsSaved,
+	}, nil
+}
+
+// Gets an object ID from an entity of type ID
+func getObjectID(rawID entity.ID) (primitive.ObjectID, error) {
+	if rawID.IsZero() {
+		return primitive.NilObjectID, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(rawID.Hex())
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("reservation.MongoRepository: failed to create object")
+	}
+	return objectID, nil
+}
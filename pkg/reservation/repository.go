@@ -0,0 +1,14 @@
+package reservation
+
+import (
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// Repository is an interface representing the ability to perform CRUD
+// operations on reservations in a database.
+type Repository interface {
+	FindByTripID(tripID entity.ID) ([]*entity.Reservation, error)
+	Create(r *entity.Reservation) (entity.ID, error)
+	Delete(r *entity.Reservation) error
+	Emissions(userID entity.ID) (*entity.EmissionsSummary, error)
+}
@@ -2,6 +2,7 @@ package reservation
 
 import (
 	"fmt"
+	"log"
 
 	"azure.com/ecovo/trip-service/pkg/entity"
 	"azure.com/ecovo/trip-service/pkg/trip"
@@ -12,17 +13,19 @@ import (
 type UseCase interface {
 	Register(r *entity.Reservation) error
 	Delete(r *entity.Reservation) error
+	Emissions(userID entity.ID) (*entity.EmissionsSummary, error)
 }
 
 // A Service handles the business logic related to reservations.
 type Service struct {
+	repo        Repository
 	tripService trip.UseCase
 }
 
 // NewService creates a reservation service to handle business logic and manipulate
 // reservations through a repository.
-func NewService(tripService trip.UseCase) *Service {
-	return &Service{tripService}
+func NewService(repo Repository, tripService trip.UseCase) *Service {
+	return &Service{repo, tripService}
 }
 
 // Register modifies trip repository based on a reservation done.
@@ -41,6 +44,11 @@ func (s *Service) Register(r *entity.Reservation) error {
 		return err
 	}
 
+	err = t.EstimateReservationEmissions(r)
+	if err != nil {
+		return err
+	}
+
 	// We remove seats we want to reserve on the trip
 	isFull := true
 	isInTrip := false
@@ -66,8 +74,20 @@ func (s *Service) Register(r *entity.Reservation) error {
 	t.Full = isFull
 	t.UpdateReservationCount(r.Seats)
 
+	r.ID, err = s.repo.Create(r)
+	if err != nil {
+		return err
+	}
+
+	// The reservation is removed if the seats cannot be reserved on the trip,
+	// so that it is not counted in the user's emissions
 	err = s.tripService.Update(t)
 	if err != nil {
+		rollbackErr := s.repo.Delete(r)
+		if rollbackErr != nil {
+			log.Println(rollbackErr)
+		}
+
 		return err
 	}
 
@@ -115,5 +135,20 @@ func (s *Service) Delete(r *entity.Reservation) error {
 		return err
 	}
 
+	err = s.repo.Delete(r)
+	if err != nil {
+		log.Println(err)
+	}
+
 	return nil
 }
+
+// Emissions sums the environmental impact of the reservations made by the
+// user with the given ID.
+func (s *Service) Emissions(userID entity.ID) (*entity.EmissionsSummary, error) {
+	if userID.IsZero() {
+		return nil, fmt.Errorf("reservation.Service: user ID is missing")
+	}
+
+	return s.repo.Emissions(userID)
+}
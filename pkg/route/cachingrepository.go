@@ -0,0 +1,219 @@
+package route
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// A CachingRepository is a repository that keeps the routes generated by
+// another repository in memory, so that trips going through the same stops at
+// the same time of the week do not need to be routed again.
+type CachingRepository struct {
+	repo Repository
+	conf *CacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	hits    int64
+	misses  int64
+}
+
+// CacheConfig contains the information required to cache routes.
+type CacheConfig struct {
+	// TTL specifies how long a route is kept in the cache.
+	TTL time.Duration
+
+	// Size specifies the maximum number of routes kept in the cache. When it
+	// is full, the least recently used route is evicted.
+	Size int
+
+	// Precision specifies the number of decimals the stops' coordinates are
+	// rounded to, so that stops a few meters apart share the same routes.
+	Precision int
+
+	// BucketSize specifies the duration of the time-of-week buckets, since
+	// routes depend on the traffic at the time they are driven.
+	BucketSize time.Duration
+}
+
+// CacheStats contains the counters of a cache.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Size   int   `json:"size"`
+}
+
+type cacheEntry struct {
+	key       string
+	route     *Route
+	expiresAt time.Time
+}
+
+const (
+	// DefaultCacheTTL represents how long a route is kept in the cache when
+	// no TTL is configured.
+	DefaultCacheTTL = 24 * time.Hour
+
+	// DefaultCacheSize represents the maximum number of routes kept in the
+	// cache when no size is configured.
+	DefaultCacheSize = 1000
+
+	// DefaultCachePrecision represents the number of decimals the coordinates
+	// are rounded to when no precision is configured (about 11 meters).
+	DefaultCachePrecision = 4
+
+	// DefaultCacheBucketSize represents the duration of the time-of-week
+	// buckets when none is configured.
+	DefaultCacheBucketSize = time.Hour
+
+	week = 7 * 24 * time.Hour
+)
+
+// NewCachingRepository creates a repository that caches the routes generated
+// by the given repository.
+func NewCachingRepository(repo Repository, conf *CacheConfig) (*CachingRepository, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("route.CachingRepository: repository is nil")
+	}
+
+	if conf == nil {
+		return nil, fmt.Errorf("route.CachingRepository: missing configuration")
+	}
+
+	if conf.TTL <= 0 {
+		return nil, fmt.Errorf("route.CachingRepository: TTL must be greater than 0")
+	}
+
+	if conf.Size <= 0 {
+		return nil, fmt.Errorf("route.CachingRepository: size must be greater than 0")
+	}
+
+	if conf.Precision < 0 {
+		return nil, fmt.Errorf("route.CachingRepository: precision must be at least 0")
+	}
+
+	if conf.BucketSize <= 0 || conf.BucketSize > week {
+		return nil, fmt.Errorf("route.CachingRepository: bucket size must be between 0 and a week")
+	}
+
+	return &CachingRepository{
+		repo:    repo,
+		conf:    conf,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}, nil
+}
+
+// GenerateRoute returns the cached route that goes through a trip's stops, or
+// generates it with the underlying repository and caches it.
+func (cr *CachingRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	key, err := cr.key(t)
+	if err != nil {
+		return nil, err
+	}
+
+	r := cr.get(key)
+	if r != nil {
+		return r, nil
+	}
+
+	r, err = cr.repo.GenerateRoute(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	cr.set(key, r)
+
+	return r.copy(), nil
+}
+
+// Stats returns the cache's hit and miss counters, along with the number of
+// routes it contains.
+func (cr *CachingRepository) Stats() *CacheStats {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	return &CacheStats{cr.hits, cr.misses, cr.order.Len()}
+}
+
+// key identifies the route of a trip by its rounded stops' coordinates, in
+// order, by the time-of-week bucket of its departure or arrival time and by
+// whether its stops are optimized.
+func (cr *CachingRepository) key(t *entity.Trip) (string, error) {
+	var anchor string
+	var at time.Time
+	if !t.LeaveAt.IsZero() {
+		anchor, at = "leave", t.LeaveAt
+	} else if !t.ArriveBy.IsZero() {
+		anchor, at = "arrive", t.ArriveBy
+	} else {
+		return "", fmt.Errorf("route.CachingRepository: arriveBy OR leaveAt must be specified")
+	}
+
+	at = at.UTC()
+	sinceWeekStart := time.Duration(at.Weekday())*24*time.Hour +
+		at.Sub(time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC))
+	bucket := int64(sinceWeekStart / cr.conf.BucketSize)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%d:%t", anchor, bucket, t.OptimizeStops)
+	for i, s := range t.Stops {
+		if s.Point == nil {
+			return "", fmt.Errorf("route.CachingRepository: stop %d has no point", i)
+		}
+		fmt.Fprintf(&b, ";%.*f,%.*f", cr.conf.Precision, s.Point.Latitude, cr.conf.Precision, s.Point.Longitude)
+	}
+
+	return b.String(), nil
+}
+
+func (cr *CachingRepository) get(key string) *Route {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	el, ok := cr.entries[key]
+	if !ok {
+		cr.misses++
+		return nil
+	}
+
+	e := el.Value.(*cacheEntry)
+	if time.Now().After(e.expiresAt) {
+		cr.order.Remove(el)
+		delete(cr.entries, key)
+		cr.misses++
+		return nil
+	}
+
+	cr.order.MoveToFront(el)
+	cr.hits++
+
+	return e.route.copy()
+}
+
+func (cr *CachingRepository) set(key string, r *Route) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	e := &cacheEntry{key, r.copy(), time.Now().Add(cr.conf.TTL)}
+	if el, ok := cr.entries[key]; ok {
+		el.Value = e
+		cr.order.MoveToFront(el)
+		return
+	}
+
+	cr.entries[key] = cr.order.PushFront(e)
+
+	for cr.order.Len() > cr.conf.Size {
+		oldest := cr.order.Back()
+		cr.order.Remove(oldest)
+		delete(cr.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,228 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// A stubRepository is a repository that returns the given errors in order,
+// then routes with the given summary. When it has a delay, it waits for it or
+// for its context to be done before responding.
+type stubRepository struct {
+	summary string
+	errs    []error
+	delay   time.Duration
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *stubRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	s.mu.Lock()
+	s.calls++
+	call := s.calls
+	s.mu.Unlock()
+
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if call <= len(s.errs) && s.errs[call-1] != nil {
+		return nil, s.errs[call-1]
+	}
+
+	legs := make([]*Leg, len(t.Stops)-1)
+	for i := range legs {
+		legs[i] = &Leg{Distance: 1000, Duration: time.Minute}
+	}
+
+	return &Route{
+		Legs:         legs,
+		Summary:      s.summary,
+		Alternatives: []*Route{{Legs: legs, Summary: s.summary + " (alternative)"}},
+	}, nil
+}
+
+func (s *stubRepository) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+var cacheLeaveAt = time.Date(2026, 10, 19, 8, 10, 0, 0, time.UTC)
+
+func newTestCachingRepository(t *testing.T, conf CacheConfig) (*CachingRepository, *stubRepository) {
+	t.Helper()
+
+	if conf.TTL == 0 {
+		conf.TTL = DefaultCacheTTL
+	}
+	if conf.Size == 0 {
+		conf.Size = DefaultCacheSize
+	}
+	if conf.Precision == 0 {
+		conf.Precision = DefaultCachePrecision
+	}
+	if conf.BucketSize == 0 {
+		conf.BucketSize = DefaultCacheBucketSize
+	}
+
+	stub := &stubRepository{summary: "A-15"}
+	repo, err := NewCachingRepository(stub, &conf)
+	if err != nil {
+		t.Fatalf("NewCachingRepository() error = %v", err)
+	}
+
+	return repo, stub
+}
+
+func newTestCachedTrip() *entity.Trip {
+	trip := newTestTrip(2)
+	trip.LeaveAt = cacheLeaveAt
+
+	return trip
+}
+
+func TestCachingRepositoryKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(trip *entity.Trip)
+		hit    bool
+	}{
+		{"same trip", func(trip *entity.Trip) {}, true},
+		{"same hour next week", func(trip *entity.Trip) { trip.LeaveAt = cacheLeaveAt.Add(week + 30*time.Minute) }, true},
+		{"same hour in another time zone", func(trip *entity.Trip) { trip.LeaveAt = cacheLeaveAt.In(time.FixedZone("EDT", -4*3600)) }, true},
+		{"next hour", func(trip *entity.Trip) { trip.LeaveAt = cacheLeaveAt.Add(time.Hour) }, false},
+		{"same hour next day", func(trip *entity.Trip) { trip.LeaveAt = cacheLeaveAt.Add(24 * time.Hour) }, false},
+		{"arriving instead of leaving", func(trip *entity.Trip) { trip.LeaveAt, trip.ArriveBy = time.Time{}, cacheLeaveAt }, false},
+		{"optimized stops", func(trip *entity.Trip) { trip.OptimizeStops = true }, false},
+		{"stop moved within the precision", func(trip *entity.Trip) { trip.Stops[0].Point.Latitude += 0.00001 }, true},
+		{"stop moved beyond the precision", func(trip *entity.Trip) { trip.Stops[0].Point.Latitude += 0.001 }, false},
+		{"reversed stops", func(trip *entity.Trip) { trip.Stops[0], trip.Stops[1] = trip.Stops[1], trip.Stops[0] }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, stub := newTestCachingRepository(t, CacheConfig{})
+
+			_, err := repo.GenerateRoute(context.Background(), newTestCachedTrip())
+			if err != nil {
+				t.Fatalf("GenerateRoute() error = %v", err)
+			}
+
+			trip := newTestCachedTrip()
+			tt.modify(trip)
+			_, err = repo.GenerateRoute(context.Background(), trip)
+			if err != nil {
+				t.Fatalf("GenerateRoute() error = %v", err)
+			}
+
+			wantCalls := 2
+			if tt.hit {
+				wantCalls = 1
+			}
+
+			if stub.callCount() != wantCalls {
+				t.Errorf("calls = %d, want %d", stub.callCount(), wantCalls)
+			}
+		})
+	}
+}
+
+func TestCachingRepositoryEviction(t *testing.T) {
+	repo, stub := newTestCachingRepository(t, CacheConfig{Size: 2})
+
+	trips := make([]*entity.Trip, 3)
+	for i := range trips {
+		trips[i] = newTestCachedTrip()
+		trips[i].LeaveAt = cacheLeaveAt.Add(time.Duration(i) * time.Hour)
+	}
+
+	// The first trip is used again before the third one is cached, so the
+	// second one is the least recently used
+	for _, i := range []int{0, 1, 0, 2, 0, 1} {
+		_, err := repo.GenerateRoute(context.Background(), trips[i])
+		if err != nil {
+			t.Fatalf("GenerateRoute() error = %v", err)
+		}
+	}
+
+	if stub.callCount() != 4 {
+		t.Errorf("calls = %d, want 4", stub.callCount())
+	}
+
+	stats := repo.Stats()
+	if stats.Hits != 2 || stats.Misses != 4 || stats.Size != 2 {
+		t.Errorf("stats = %+v, want 2 hits, 4 misses and 2 routes", stats)
+	}
+}
+
+func TestCachingRepositoryExpiration(t *testing.T) {
+	repo, stub := newTestCachingRepository(t, CacheConfig{TTL: 20 * time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GenerateRoute(context.Background(), newTestCachedTrip())
+		if err != nil {
+			t.Fatalf("GenerateRoute() error = %v", err)
+		}
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	_, err := repo.GenerateRoute(context.Background(), newTestCachedTrip())
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	if stub.callCount() != 2 {
+		t.Errorf("calls = %d, want 2 since the route expired", stub.callCount())
+	}
+}
+
+func TestCachingRepositoryErrorsNotCached(t *testing.T) {
+	repo, stub := newTestCachingRepository(t, CacheConfig{})
+	stub.errs = []error{errors.New("unavailable")}
+
+	_, err := repo.GenerateRoute(context.Background(), newTestCachedTrip())
+	if err == nil {
+		t.Fatal("GenerateRoute() error = nil, want an error")
+	}
+
+	_, err = repo.GenerateRoute(context.Background(), newTestCachedTrip())
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	if stub.callCount() != 2 {
+		t.Errorf("calls = %d, want 2", stub.callCount())
+	}
+}
+
+func TestCachingRepositoryCopies(t *testing.T) {
+	repo, _ := newTestCachingRepository(t, CacheConfig{})
+
+	r, err := repo.GenerateRoute(context.Background(), newTestCachedTrip())
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	r.Legs[0].Distance = 0
+	r.Alternatives[0].Summary = "modified"
+
+	r, err = repo.GenerateRoute(context.Background(), newTestCachedTrip())
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	if r.Legs[0].Distance != 1000 || r.Alternatives[0].Summary != "A-15 (alternative)" {
+		t.Errorf("route = %+v, want the route as it was generated", r)
+	}
+}
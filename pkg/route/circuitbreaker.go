@@ -0,0 +1,74 @@
+package route
+
+import (
+	"sync"
+	"time"
+)
+
+// A circuitBreaker stops calls to a failing provider for a while, so that
+// requests fail fast instead of waiting on it. It opens after a number of
+// consecutive failures, and lets a single trial call through once the cooldown
+// has elapsed. The trial call closes it if it succeeds, or opens it again if
+// it fails.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow checks whether a call can be made.
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures < cb.threshold {
+		return true
+	}
+
+	if cb.trial || time.Now().Before(cb.openUntil) {
+		return false
+	}
+
+	cb.trial = true
+
+	return true
+}
+
+// success records a call that succeeded.
+func (cb *circuitBreaker) success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.trial = false
+}
+
+// cancel records a call that was abandoned before its outcome was known, such
+// as when the caller's context is done. It is neither a success nor a failure,
+// but it ends the trial call, so that another one can be made.
+func (cb *circuitBreaker) cancel() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trial = false
+}
+
+// failure records a call that failed.
+func (cb *circuitBreaker) failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.trial = false
+	if cb.failures >= cb.threshold {
+		cb.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
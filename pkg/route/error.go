@@ -0,0 +1,21 @@
+package route
+
+// An UnavailableError is an error that represents that the routing provider
+// cannot be reached, so routes cannot be computed for now.
+type UnavailableError struct {
+	msg string
+}
+
+func (e UnavailableError) Error() string {
+	return e.msg
+}
+
+// A QuotaExceededError is an error that represents that the daily quota of
+// calls to a routing provider, or of a user, has been reached.
+type QuotaExceededError struct {
+	msg string
+}
+
+func (e QuotaExceededError) Error() string {
+	return e.msg
+}
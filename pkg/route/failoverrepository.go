@@ -0,0 +1,140 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// A FailoverRepository is a repository that generates routes with the first of
+// several routing providers that succeeds, in priority order, so that a
+// provider's outage does not prevent routing trips.
+type FailoverRepository struct {
+	providers []*Provider
+	conf      *FailoverConfig
+
+	mu    sync.Mutex
+	stats map[string]*ProviderStats
+}
+
+// A Provider is a named repository used by a FailoverRepository.
+type Provider struct {
+	Name       string
+	Repository Repository
+}
+
+// FailoverConfig contains the information required to fail over between
+// routing providers.
+type FailoverConfig struct {
+	// Timeout specifies the amount of time a provider has to generate a
+	// route, retries included, before the next one is tried.
+	Timeout time.Duration
+}
+
+// ProviderStats contains the counters of a routing provider.
+type ProviderStats struct {
+	Successes int64 `json:"successes"`
+	Failures  int64 `json:"failures"`
+}
+
+const (
+	// DefaultFailoverTimeout represents the amount of time a provider has to
+	// generate a route when none is configured.
+	DefaultFailoverTimeout = 15 * time.Second
+)
+
+// NewFailoverRepository creates a repository that tries the given providers in
+// order.
+func NewFailoverRepository(providers []*Provider, conf *FailoverConfig) (*FailoverRepository, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("route.FailoverRepository: no providers")
+	}
+
+	if conf == nil {
+		return nil, fmt.Errorf("route.FailoverRepository: missing configuration")
+	}
+
+	if conf.Timeout <= 0 {
+		return nil, fmt.Errorf("route.FailoverRepository: timeout must be greater than 0")
+	}
+
+	stats := make(map[string]*ProviderStats, len(providers))
+	for _, p := range providers {
+		if p == nil || p.Repository == nil {
+			return nil, fmt.Errorf("route.FailoverRepository: provider is nil")
+		}
+
+		if _, ok := stats[p.Name]; ok {
+			return nil, fmt.Errorf("route.FailoverRepository: duplicate provider \"%s\"", p.Name)
+		}
+		stats[p.Name] = &ProviderStats{}
+	}
+
+	return &FailoverRepository{
+		providers: providers,
+		conf:      conf,
+		stats:     stats,
+	}, nil
+}
+
+// GenerateRoute generates the route that goes through a trip's stops with the
+// first provider that succeeds, and records its name on the route. An
+// UnavailableError is returned when all of them fail.
+func (fr *FailoverRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	errs := make([]string, 0, len(fr.providers))
+	for _, p := range fr.providers {
+		providerCtx, cancel := context.WithTimeout(ctx, fr.conf.Timeout)
+		r, err := p.Repository.GenerateRoute(providerCtx, t)
+		cancel()
+
+		// The request's own context being done is not the provider's fault
+		if err != nil && ctx.Err() != nil {
+			return nil, fmt.Errorf("route.FailoverRepository: error generating route, %s", ctx.Err())
+		}
+
+		fr.record(p.Name, err == nil)
+
+		if err == nil {
+			r.Provider = p.Name
+			for _, alternative := range r.Alternatives {
+				alternative.Provider = p.Name
+			}
+			return r, nil
+		}
+
+		log.Printf("route.FailoverRepository: provider \"%s\" failed (%s)", p.Name, err)
+		errs = append(errs, fmt.Sprintf("%s: %s", p.Name, err))
+	}
+
+	return nil, UnavailableError{fmt.Sprintf("route.FailoverRepository: all providers failed (%s)", strings.Join(errs, "; "))}
+}
+
+// Stats returns the success and failure counters of each provider, by name.
+func (fr *FailoverRepository) Stats() map[string]*ProviderStats {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	stats := make(map[string]*ProviderStats, len(fr.stats))
+	for name, s := range fr.stats {
+		copied := *s
+		stats[name] = &copied
+	}
+
+	return stats
+}
+
+func (fr *FailoverRepository) record(name string, success bool) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	if success {
+		fr.stats[name].Successes++
+	} else {
+		fr.stats[name].Failures++
+	}
+}
@@ -0,0 +1,144 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestFailoverRepository(t *testing.T, stubs ...*stubRepository) *FailoverRepository {
+	t.Helper()
+
+	providers := make([]*Provider, len(stubs))
+	for i, stub := range stubs {
+		providers[i] = &Provider{stub.summary, stub}
+	}
+
+	repo, err := NewFailoverRepository(providers, &FailoverConfig{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewFailoverRepository() error = %v", err)
+	}
+
+	return repo
+}
+
+func TestFailoverRepositoryGenerateRoute(t *testing.T) {
+	failure := errors.New("unavailable")
+
+	tests := []struct {
+		name         string
+		stubs        []*stubRepository
+		wantProvider string
+		wantCalls    []int
+	}{
+		{
+			"first provider succeeds",
+			[]*stubRepository{{summary: "google"}, {summary: "osrm"}},
+			"google",
+			[]int{1, 0},
+		},
+		{
+			"first provider fails",
+			[]*stubRepository{{summary: "google", errs: []error{failure}}, {summary: "osrm"}},
+			"osrm",
+			[]int{1, 1},
+		},
+		{
+			"first provider times out",
+			[]*stubRepository{{summary: "google", delay: time.Second}, {summary: "osrm"}},
+			"osrm",
+			[]int{1, 1},
+		},
+		{
+			"all providers fail",
+			[]*stubRepository{{summary: "google", errs: []error{failure}}, {summary: "osrm", errs: []error{failure}}},
+			"",
+			[]int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestFailoverRepository(t, tt.stubs...)
+
+			r, err := repo.GenerateRoute(context.Background(), newTestTrip(2))
+
+			for i, stub := range tt.stubs {
+				if stub.callCount() != tt.wantCalls[i] {
+					t.Errorf("calls to %s = %d, want %d", stub.summary, stub.callCount(), tt.wantCalls[i])
+				}
+			}
+
+			if tt.wantProvider == "" {
+				if _, ok := err.(UnavailableError); !ok {
+					t.Fatalf("GenerateRoute() error = %v, want an UnavailableError", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GenerateRoute() error = %v", err)
+			}
+
+			if r.Provider != tt.wantProvider || r.Alternatives[0].Provider != tt.wantProvider {
+				t.Errorf("providers = (%s, %s), want %s for the route and its alternatives", r.Provider, r.Alternatives[0].Provider, tt.wantProvider)
+			}
+		})
+	}
+}
+
+func TestFailoverRepositoryCancel(t *testing.T) {
+	google := &stubRepository{summary: "google", delay: time.Second}
+	osrm := &stubRepository{summary: "osrm"}
+	repo := newTestFailoverRepository(t, google, osrm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := repo.GenerateRoute(ctx, newTestTrip(2))
+	if err == nil {
+		t.Fatal("GenerateRoute() error = nil, want an error")
+	}
+	if _, ok := err.(UnavailableError); ok {
+		t.Fatalf("GenerateRoute() error = %v, want the context's error", err)
+	}
+
+	if osrm.callCount() != 0 {
+		t.Errorf("calls to osrm = %d, want 0 since the request was cancelled", osrm.callCount())
+	}
+
+	if stats := repo.Stats()["google"]; stats.Failures != 0 {
+		t.Errorf("google stats = %+v, want no failure since the request was cancelled", stats)
+	}
+}
+
+func TestFailoverRepositoryStats(t *testing.T) {
+	google := &stubRepository{summary: "google", errs: []error{errors.New("unavailable")}}
+	osrm := &stubRepository{summary: "osrm"}
+	repo := newTestFailoverRepository(t, google, osrm)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GenerateRoute(context.Background(), newTestTrip(2))
+		if err != nil {
+			t.Fatalf("GenerateRoute() error = %v", err)
+		}
+	}
+
+	stats := repo.Stats()
+	if stats["google"].Successes != 1 || stats["google"].Failures != 1 {
+		t.Errorf("google stats = %+v, want 1 success and 1 failure", stats["google"])
+	}
+	if stats["osrm"].Successes != 1 || stats["osrm"].Failures != 0 {
+		t.Errorf("osrm stats = %+v, want 1 success", stats["osrm"])
+	}
+}
+
+func TestNewFailoverRepositoryDuplicateProvider(t *testing.T) {
+	stub := &stubRepository{summary: "google"}
+
+	_, err := NewFailoverRepository([]*Provider{{"google", stub}, {"google", stub}}, &FailoverConfig{Timeout: time.Second})
+	if err == nil {
+		t.Error("NewFailoverRepository() error = nil, want an error")
+	}
+}
@@ -2,8 +2,13 @@ package route
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"azure.com/ecovo/trip-service/pkg/entity"
@@ -12,106 +17,299 @@ import (
 
 // GoogleMapsRepository structure
 type GoogleMapsRepository struct {
-	client *maps.Client
+	client  *maps.Client
+	conf    *GoogleMapsConfig
+	breaker *circuitBreaker
+}
+
+// GoogleMapsConfig contains the information required to make requests to the
+// Google Maps Directions API, and how to handle its failures.
+type GoogleMapsConfig struct {
+	// APIKey specifies the key used to authenticate to the API.
+	APIKey string
+
+	// BaseURL specifies where the API is hosted, or is empty to use Google's.
+	BaseURL string
+
+	// Timeout specifies how long to wait for each call to the API.
+	Timeout time.Duration
+
+	// MaxRetries specifies how many times a call that failed with a
+	// retryable error is retried.
+	MaxRetries int
+
+	// RetryBackoff specifies how long to wait before the first retry. The
+	// wait doubles on every retry.
+	RetryBackoff time.Duration
+
+	// BreakerThreshold specifies the number of consecutive failures after
+	// which calls to the API are stopped.
+	BreakerThreshold int
+
+	// BreakerCooldown specifies how long calls to the API are stopped.
+	BreakerCooldown time.Duration
 }
 
 const (
-	// PricePerKilometer represents the price (in dollars) established by the Canadian
-	// government as the maximum allocation for vehicle usage.
-	// https://www.canada.ca/fr/agence-revenu/services/impot/entreprises/sujets/retenues-paie/avantages-allocations/automobile/allocations-frais-automobile-vehicule-a-moteur/taux-allocations-frais-automobile.html
-	PricePerKilometer = 0.58
+	// DefaultGoogleMapsTimeout represents how long to wait for each call to
+	// the API when no timeout is configured.
+	DefaultGoogleMapsTimeout = 5 * time.Second
+
+	// DefaultGoogleMapsMaxRetries represents how many times a call is retried
+	// when no maximum is configured.
+	DefaultGoogleMapsMaxRetries = 2
+
+	// DefaultGoogleMapsRetryBackoff represents how long to wait before the
+	// first retry when no backoff is configured.
+	DefaultGoogleMapsRetryBackoff = 200 * time.Millisecond
+
+	// DefaultGoogleMapsBreakerThreshold represents the number of consecutive
+	// failures after which calls are stopped when no threshold is configured.
+	DefaultGoogleMapsBreakerThreshold = 5
+
+	// DefaultGoogleMapsBreakerCooldown represents how long calls are stopped
+	// when no cooldown is configured.
+	DefaultGoogleMapsBreakerCooldown = 30 * time.Second
 )
 
 // NewGoogleMapsRepository creates the repository
-func NewGoogleMapsRepository(client *maps.Client) (Repository, error) {
-	if client == nil {
-		return nil, fmt.Errorf("route.GoogleRepository: client is nil")
+func NewGoogleMapsRepository(conf *GoogleMapsConfig) (Repository, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: missing configuration")
+	}
+
+	if conf.Timeout <= 0 {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: timeout must be greater than 0")
+	}
+
+	if conf.MaxRetries < 0 {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: max retries must be at least 0")
+	}
+
+	if conf.RetryBackoff < 0 {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: retry backoff must be at least 0")
+	}
+
+	if conf.BreakerThreshold <= 0 {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: breaker threshold must be greater than 0")
+	}
+
+	if conf.BreakerCooldown <= 0 {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: breaker cooldown must be greater than 0")
+	}
+
+	options := []maps.ClientOption{
+		maps.WithAPIKey(conf.APIKey),
+		maps.WithHTTPClient(&http.Client{Transport: &statusTransport{http.DefaultTransport}}),
+	}
+	if conf.BaseURL != "" {
+		options = append(options, maps.WithBaseURL(conf.BaseURL))
+	}
+
+	client, err := maps.NewClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: failed to create client (%s)", err)
 	}
 
 	return &GoogleMapsRepository{
-		client: client,
+		client:  client,
+		conf:    conf,
+		breaker: newCircuitBreaker(conf.BreakerThreshold, conf.BreakerCooldown),
 	}, nil
 }
 
-// GenerateRoute allows a user to generate route information for a given trip
-func (gr *GoogleMapsRepository) GenerateRoute(t *entity.Trip) error {
-	var wp = make([]string, len(t.Stops))
-	for i, s := range t.Stops {
-		wp[i] = s.Point.String()
+// GenerateRoute asks the Google Maps Directions API for the route that goes
+// through a trip's stops, and for alternatives when the trip has no
+// intermediate stops, since the API does not suggest any otherwise. Calls
+// that fail with a retryable error are retried with an increasing backoff, and
+// an UnavailableError is returned when the API keeps failing.
+func (gr *GoogleMapsRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	var wp = make([]string, 0, len(t.Stops))
+	for _, s := range t.Stops[1 : len(t.Stops)-1] {
+		wp = append(wp, s.Point.String())
 	}
 
-	var dr *maps.DirectionsRequest
+	dr := &maps.DirectionsRequest{
+		Origin:       t.Stops[0].Point.String(),
+		Destination:  t.Stops[len(t.Stops)-1].Point.String(),
+		Waypoints:    wp,
+		Optimize:     t.OptimizeStops,
+		Alternatives: len(t.Stops) == 2,
+		// The instructions are requested in English, since it is the only
+		// language in which tolls are detected
+		Language: "en",
+	}
 
 	if t.LeaveAt.IsZero() && !t.ArriveBy.IsZero() {
-		dr = &maps.DirectionsRequest{
-			Origin:      t.Stops[0].Point.String(),
-			Destination: t.Stops[len(t.Stops)-1].Point.String(),
-			Waypoints:   wp,
-			ArrivalTime: strconv.FormatInt(t.ArriveBy.Unix(), 10),
-		}
-	} else if !t.LeaveAt.IsZero() && t.ArriveBy.IsZero() {
-		dr = &maps.DirectionsRequest{
-			Origin:        t.Stops[0].Point.String(),
-			Destination:   t.Stops[len(t.Stops)-1].Point.String(),
-			Waypoints:     wp,
-			DepartureTime: strconv.FormatInt(t.LeaveAt.Unix(), 10),
-		}
-	} else if !t.LeaveAt.IsZero() && !t.ArriveBy.IsZero() {
-		dr = &maps.DirectionsRequest{
-			Origin:        t.Stops[0].Point.String(),
-			Destination:   t.Stops[len(t.Stops)-1].Point.String(),
-			Waypoints:     wp,
-			DepartureTime: strconv.FormatInt(t.LeaveAt.Unix(), 10),
-		}
+		dr.ArrivalTime = strconv.FormatInt(t.ArriveBy.Unix(), 10)
+	} else if !t.LeaveAt.IsZero() {
+		dr.DepartureTime = strconv.FormatInt(t.LeaveAt.Unix(), 10)
 	} else {
-		return fmt.Errorf("trip.GoogleMapsRepository: arriveBy OR leaveAt must be specified")
+		return nil, fmt.Errorf("route.GoogleMapsRepository: arriveBy OR leaveAt must be specified")
 	}
 
-	r, _, err := gr.client.Directions(context.Background(), dr)
+	r, err := gr.directions(ctx, dr)
 	if err != nil {
-		return fmt.Errorf("trip.GoogleMapsRepository: error getting directions, %s", err)
+		return nil, err
 	}
 
-	// Here we modify our trip data with google maps route generated
-	if len(r) > 0 {
-		route := r[0]
+	if len(r) == 0 {
+		return nil, fmt.Errorf("route.GoogleMapsRepository: no routes found in google map repository")
+	}
 
-		if t.LeaveAt.IsZero() {
-			leaveAt := t.ArriveBy
-			for i := range route.Legs {
-				t.TotalDistance += route.Legs[i].Distance.Meters
-				leaveAt = leaveAt.Add(-(route.Legs[i].Duration) * time.Nanosecond)
-			}
-			t.LeaveAt = leaveAt
+	routes := make([]*Route, len(r))
+	for i, gRoute := range r {
+		routes[i] = newRouteFromGoogleMaps(gRoute, t.OptimizeStops)
+	}
+	routes[0].Alternatives = routes[1:]
+
+	return routes[0], nil
+}
+
+func newRouteFromGoogleMaps(r maps.Route, optimized bool) *Route {
+	legs := make([]*Leg, len(r.Legs))
+	for i, l := range r.Legs {
+		legs[i] = &Leg{
+			Distance: l.Distance.Meters,
+			Duration: l.Duration * time.Nanosecond,
 		}
+	}
 
-		if t.ArriveBy.IsZero() {
-			arriveBy := t.LeaveAt
-			for i := range route.Legs {
-				t.TotalDistance += route.Legs[i].Distance.Meters
-				arriveBy = arriveBy.Add(route.Legs[i].Duration * time.Nanosecond)
+	var order []int
+	if optimized {
+		order = r.WaypointOrder
+	}
+
+	return &Route{
+		Legs:     legs,
+		Polyline: r.OverviewPolyline.Points,
+		Order:    order,
+		Summary:  r.Summary,
+		Tolls:    hasTolls(r),
+	}
+}
+
+// hasTolls reports whether the Google Maps route has tolls. The Directions API
+// has no field for them, so they are only detected when its warnings or its
+// steps' instructions mention them in English. A route whose tolls are not
+// mentioned, or that was requested in another language, is reported as having
+// none.
+func hasTolls(r maps.Route) bool {
+	for _, w := range r.Warnings {
+		if strings.Contains(strings.ToLower(w), "toll") {
+			return true
+		}
+	}
+
+	for _, l := range r.Legs {
+		for _, s := range l.Steps {
+			if strings.Contains(strings.ToLower(s.HTMLInstructions), "toll road") {
+				return true
 			}
-			t.ArriveBy = arriveBy
 		}
+	}
 
-		var previousTimeStamp time.Time
+	return false
+}
 
-		for i, s := range t.Stops {
-			if i == 0 {
-				s.TimeStamp = t.LeaveAt
-			} else if i == (len(t.Stops) - 1) {
-				s.TimeStamp = t.ArriveBy
-			} else {
-				s.TimeStamp = previousTimeStamp.Add(route.Legs[i].Duration * time.Nanosecond)
-			}
+// directions calls the Directions API, retrying and recording the failures in
+// the circuit breaker.
+func (gr *GoogleMapsRepository) directions(ctx context.Context, dr *maps.DirectionsRequest) ([]maps.Route, error) {
+	backoff := gr.conf.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if !gr.breaker.allow() {
+			return nil, UnavailableError{"routing unavailable, the Google Maps Directions API is failing"}
+		}
 
-			previousTimeStamp = s.TimeStamp
+		callCtx, cancel := context.WithTimeout(ctx, gr.conf.Timeout)
+		r, _, err := gr.client.Directions(callCtx, dr)
+		cancel()
+
+		if err == nil {
+			gr.breaker.success()
+			return r, nil
 		}
 
-		t.TotalTripPrice = float64(t.TotalDistance/1000.0) * PricePerKilometer
-	} else {
-		return fmt.Errorf("trip.GoogleMapsRepository: no trips found in google map repository")
+		// The request's URL contains the API key, so it is left out
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		// The request's own context being done is not the provider's fault
+		if ctx.Err() != nil {
+			gr.breaker.cancel()
+			return nil, fmt.Errorf("route.GoogleMapsRepository: error getting directions, %s", ctx.Err())
+		}
+
+		if !isRetryable(err) {
+			gr.breaker.success()
+			return nil, fmt.Errorf("route.GoogleMapsRepository: error getting directions, %s", err)
+		}
+
+		gr.breaker.failure()
+
+		if attempt >= gr.conf.MaxRetries {
+			return nil, UnavailableError{fmt.Sprintf("routing unavailable, error getting directions after %d attempts, %s", attempt+1, err)}
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("route.GoogleMapsRepository: error getting directions, %s", ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryable checks whether an error returned by the Directions API is
+// transient, in which case the call can be retried.
+func isRetryable(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return strings.HasPrefix(err.Error(), "maps: UNKNOWN_ERROR") ||
+		strings.HasPrefix(err.Error(), "maps: OVER_QUERY_LIMIT")
+}
+
+// An httpStatusError is an error that represents that the API responded with
+// an error status code.
+type httpStatusError struct {
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.code)
+}
+
+// A statusTransport turns the responses with a rate limiting or server error
+// status code into errors, since the Google Maps client only reports the
+// status contained in the response's body.
+type statusTransport struct {
+	next http.RoundTripper
+}
+
+func (st *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := st.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		resp.Body.Close()
+		return nil, &httpStatusError{resp.StatusCode}
 	}
 
-	return nil
+	return resp, nil
 }
@@ -0,0 +1,226 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const googleMapsDirectionsResponse = `{
+	"status": "OK",
+	"routes": [{
+		"summary": "A-15",
+		"overview_polyline": {"points": "_p~iF~ps|U_ulLnnqC"},
+		"legs": [{
+			"distance": {"value": 12000, "text": "12 km"},
+			"duration": {"value": 900, "text": "15 mins"}
+		}]
+	}]
+}`
+
+// newTestGoogleMapsRepository creates a repository that makes its requests to
+// a local server, which responds with the given status codes in order, then
+// with a successful response. It returns the number of requests made to the
+// server.
+func newTestGoogleMapsRepository(t *testing.T, conf GoogleMapsConfig, statuses ...int) (Repository, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1))
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(googleMapsDirectionsResponse))
+	}))
+	t.Cleanup(server.Close)
+
+	conf.APIKey = "test"
+	conf.BaseURL = server.URL
+	if conf.Timeout == 0 {
+		conf.Timeout = time.Second
+	}
+	if conf.BreakerThreshold == 0 {
+		conf.BreakerThreshold = DefaultGoogleMapsBreakerThreshold
+	}
+	if conf.BreakerCooldown == 0 {
+		conf.BreakerCooldown = DefaultGoogleMapsBreakerCooldown
+	}
+
+	repo, err := NewGoogleMapsRepository(&conf)
+	if err != nil {
+		t.Fatalf("NewGoogleMapsRepository() error = %v", err)
+	}
+
+	return repo, &calls
+}
+
+func generateTestRoute(ctx context.Context, repo Repository) (*Route, error) {
+	trip := newTestTrip(2)
+	trip.LeaveAt = time.Now().Add(time.Hour)
+
+	return repo.GenerateRoute(ctx, trip)
+}
+
+func TestNewGoogleMapsRepositoryInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		conf GoogleMapsConfig
+	}{
+		{"no timeout", GoogleMapsConfig{BreakerThreshold: 1, BreakerCooldown: time.Second}},
+		{"negative retries", GoogleMapsConfig{Timeout: time.Second, MaxRetries: -1, BreakerThreshold: 1, BreakerCooldown: time.Second}},
+		{"no breaker threshold", GoogleMapsConfig{Timeout: time.Second, BreakerCooldown: time.Second}},
+		{"no breaker cooldown", GoogleMapsConfig{Timeout: time.Second, BreakerThreshold: 1}},
+		{"negative breaker cooldown", GoogleMapsConfig{Timeout: time.Second, BreakerThreshold: 1, BreakerCooldown: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.APIKey = "test"
+
+			_, err := NewGoogleMapsRepository(&tt.conf)
+			if err == nil {
+				t.Error("NewGoogleMapsRepository() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestGoogleMapsRepositoryRetry(t *testing.T) {
+	repo, calls := newTestGoogleMapsRepository(t, GoogleMapsConfig{MaxRetries: 2}, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+	r, err := generateTestRoute(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	if *calls != 3 {
+		t.Errorf("calls = %d, want 3", *calls)
+	}
+
+	if len(r.Legs) != 1 || r.Legs[0].Distance != 12000 || r.Legs[0].Duration != 15*time.Minute || r.Summary != "A-15" {
+		t.Errorf("route = %+v, want the route of the response", r)
+	}
+}
+
+func TestGoogleMapsRepositoryRetriesExhausted(t *testing.T) {
+	repo, calls := newTestGoogleMapsRepository(t, GoogleMapsConfig{MaxRetries: 1}, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	_, err := generateTestRoute(context.Background(), repo)
+	if _, ok := err.(UnavailableError); !ok {
+		t.Fatalf("GenerateRoute() error = %v, want an UnavailableError", err)
+	}
+
+	if *calls != 2 {
+		t.Errorf("calls = %d, want 2", *calls)
+	}
+}
+
+func TestGoogleMapsRepositoryBreakerOpen(t *testing.T) {
+	repo, calls := newTestGoogleMapsRepository(t, GoogleMapsConfig{
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Hour,
+	}, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	for i := 0; i < 2; i++ {
+		_, err := generateTestRoute(context.Background(), repo)
+		if err == nil {
+			t.Fatalf("GenerateRoute() #%d error = nil, want an error", i+1)
+		}
+	}
+
+	_, err := generateTestRoute(context.Background(), repo)
+	if _, ok := err.(UnavailableError); !ok {
+		t.Fatalf("GenerateRoute() error = %v, want an UnavailableError", err)
+	}
+
+	if *calls != 2 {
+		t.Errorf("calls = %d, want 2 since the breaker is open", *calls)
+	}
+}
+
+func TestGoogleMapsRepositoryBreakerHalfOpen(t *testing.T) {
+	cooldown := 20 * time.Millisecond
+	repo, calls := newTestGoogleMapsRepository(t, GoogleMapsConfig{
+		BreakerThreshold: 1,
+		BreakerCooldown:  cooldown,
+	}, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	_, err := generateTestRoute(context.Background(), repo)
+	if err == nil {
+		t.Fatal("GenerateRoute() error = nil, want an error")
+	}
+
+	// The trial call fails, so the breaker opens again
+	time.Sleep(2 * cooldown)
+	_, err = generateTestRoute(context.Background(), repo)
+	if err == nil {
+		t.Fatal("GenerateRoute() error = nil, want an error")
+	}
+
+	_, err = generateTestRoute(context.Background(), repo)
+	if _, ok := err.(UnavailableError); !ok {
+		t.Fatalf("GenerateRoute() error = %v, want an UnavailableError", err)
+	}
+
+	// The trial call succeeds, so the breaker closes
+	time.Sleep(2 * cooldown)
+	for i := 0; i < 2; i++ {
+		_, err = generateTestRoute(context.Background(), repo)
+		if err != nil {
+			t.Fatalf("GenerateRoute() error = %v", err)
+		}
+	}
+
+	if *calls != 4 {
+		t.Errorf("calls = %d, want 4", *calls)
+	}
+}
+
+func TestGoogleMapsRepositoryCancel(t *testing.T) {
+	cooldown := 20 * time.Millisecond
+	repo, calls := newTestGoogleMapsRepository(t, GoogleMapsConfig{
+		MaxRetries:       2,
+		RetryBackoff:     time.Hour,
+		BreakerThreshold: 1,
+		BreakerCooldown:  cooldown,
+	}, http.StatusInternalServerError)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// The call is cancelled while waiting to retry
+	_, err := generateTestRoute(ctx, repo)
+	if err == nil {
+		t.Fatal("GenerateRoute() error = nil, want an error")
+	}
+	if _, ok := err.(UnavailableError); ok {
+		t.Fatalf("GenerateRoute() error = %v, want the context's error", err)
+	}
+
+	// The trial call is cancelled before it is made, which must not keep the
+	// breaker from letting another trial call through
+	time.Sleep(2 * cooldown)
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	_, err = generateTestRoute(ctx, repo)
+	if err == nil {
+		t.Fatal("GenerateRoute() error = nil, want an error")
+	}
+
+	_, err = generateTestRoute(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	if *calls != 2 {
+		t.Errorf("calls = %d, want 2 since the cancelled call is not made", *calls)
+	}
+}
@@ -0,0 +1,103 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// A GreatCircleRepository is a repository that computes routes without an
+// external routing provider. It estimates the distance between stops from the
+// great-circle distance between them, and the duration from an average speed.
+//
+// When asked to optimize the order of the stops, it estimates the shortest one
+// from the great-circle distances.
+//
+// It makes it possible to create trips in development and tests, where no
+// routing provider is available.
+type GreatCircleRepository struct {
+	conf *GreatCircleConfig
+}
+
+// GreatCircleConfig contains the information required to estimate routes from
+// great-circle distances.
+type GreatCircleConfig struct {
+	// AverageSpeed specifies the average driving speed, in kilometers per
+	// hour.
+	AverageSpeed float64
+
+	// RoadDistanceFactor specifies the factor by which the great-circle
+	// distance is multiplied to estimate the road distance, since roads are
+	// rarely straight.
+	RoadDistanceFactor float64
+}
+
+const (
+	// DefaultAverageSpeed represents the average driving speed, in kilometers
+	// per hour, used when none is configured.
+	DefaultAverageSpeed = 70.0
+
+	// DefaultRoadDistanceFactor represents the factor used to estimate the
+	// road distance when none is configured.
+	DefaultRoadDistanceFactor = 1.3
+)
+
+// NewGreatCircleRepository creates a repository that estimates routes from
+// great-circle distances.
+func NewGreatCircleRepository(conf *GreatCircleConfig) (Repository, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("route.GreatCircleRepository: missing configuration")
+	}
+
+	if conf.AverageSpeed <= 0 {
+		return nil, fmt.Errorf("route.GreatCircleRepository: average speed must be greater than 0")
+	}
+
+	if conf.RoadDistanceFactor < 1 {
+		return nil, fmt.Errorf("route.GreatCircleRepository: road distance factor must be at least 1")
+	}
+
+	return &GreatCircleRepository{conf}, nil
+}
+
+// GenerateRoute estimates the route that goes through a trip's stops
+func (gr *GreatCircleRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	points := make([]*entity.Point, len(t.Stops))
+	for i, s := range t.Stops {
+		if s.Point == nil {
+			return nil, fmt.Errorf("route.GreatCircleRepository: stop %d has no point", i)
+		}
+		points[i] = s.Point
+	}
+
+	var order []int
+	if t.OptimizeStops {
+		order = optimizeOrder(points)
+
+		reordered := make([]*entity.Point, 0, len(points))
+		reordered = append(reordered, points[0])
+		for _, o := range order {
+			reordered = append(reordered, points[o+1])
+		}
+		points = append(reordered, points[len(points)-1])
+	}
+
+	legs := make([]*Leg, len(points)-1)
+	for i := range legs {
+		distance := points[i].DistanceTo(points[i+1]) * gr.conf.RoadDistanceFactor
+		hours := distance / 1000 / gr.conf.AverageSpeed
+
+		legs[i] = &Leg{
+			Distance: int(distance),
+			Duration: time.Duration(hours * float64(time.Hour)).Round(time.Second),
+		}
+	}
+
+	return &Route{
+		Legs:     legs,
+		Polyline: entity.EncodePolyline(points),
+		Order:    order,
+	}, nil
+}
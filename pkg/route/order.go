@@ -0,0 +1,61 @@
+package route
+
+import (
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// optimizeOrder estimates the shortest order in which to visit the
+// intermediate points between the first and the last one, which stay fixed.
+// It starts from the nearest neighbour order and improves it by reversing
+// sections of the path (2-opt) as long as it gets shorter. The order is
+// returned as indices among the intermediate points.
+func optimizeOrder(points []*entity.Point) []int {
+	if len(points) < 3 {
+		return []int{}
+	}
+
+	// path contains the indices of the points, in the order they are visited
+	path := make([]int, 0, len(points))
+	path = append(path, 0)
+
+	visited := make([]bool, len(points))
+	for len(path) < len(points)-1 {
+		last := points[path[len(path)-1]]
+		next := -1
+		for i := 1; i < len(points)-1; i++ {
+			if visited[i] {
+				continue
+			}
+
+			if next < 0 || last.DistanceTo(points[i]) < last.DistanceTo(points[next]) {
+				next = i
+			}
+		}
+		visited[next] = true
+		path = append(path, next)
+	}
+	path = append(path, len(points)-1)
+
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < len(path)-2; i++ {
+			for j := i + 1; j < len(path)-1; j++ {
+				a, b := points[path[i-1]], points[path[i]]
+				c, d := points[path[j]], points[path[j+1]]
+				if a.DistanceTo(c)+b.DistanceTo(d) < a.DistanceTo(b)+c.DistanceTo(d) {
+					for l, r := i, j; l < r; l, r = l+1, r-1 {
+						path[l], path[r] = path[r], path[l]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+
+	order := make([]int, len(path)-2)
+	for i, p := range path[1 : len(path)-1] {
+		order[i] = p - 1
+	}
+
+	return order
+}
@@ -0,0 +1,238 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// An OSRMRepository is a repository that computes routes using the route
+// service of an OSRM (Open Source Routing Machine) HTTP API, which can be
+// self-hosted.
+type OSRMRepository struct {
+	conf   *OSRMConfig
+	client *http.Client
+}
+
+// OSRMConfig contains the information required to make requests to an OSRM
+// HTTP API.
+type OSRMConfig struct {
+	// URL specifies the base URL where the API is hosted (ex.
+	// http://localhost:5000).
+	URL string
+
+	// Profile specifies the mode of transportation to use to compute the
+	// routes.
+	Profile string
+}
+
+const (
+	// DefaultOSRMProfile represents the profile used when none is configured.
+	DefaultOSRMProfile = "driving"
+
+	// DefaultOSRMTimeout represents the amount of time to wait for the API to
+	// respond when none is configured.
+	DefaultOSRMTimeout = 10 * time.Second
+)
+
+type osrmResponse struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Routes    []*osrmRoute    `json:"routes"`
+	Trips     []*osrmRoute    `json:"trips"`
+	Waypoints []*osrmWaypoint `json:"waypoints"`
+}
+
+type osrmRoute struct {
+	Distance float64    `json:"distance"`
+	Duration float64    `json:"duration"`
+	Geometry string     `json:"geometry"`
+	Legs     []*osrmLeg `json:"legs"`
+}
+
+type osrmWaypoint struct {
+	WaypointIndex int `json:"waypoint_index"`
+}
+
+type osrmLeg struct {
+	Distance float64     `json:"distance"`
+	Duration float64     `json:"duration"`
+	Summary  string      `json:"summary"`
+	Steps    []*osrmStep `json:"steps"`
+}
+
+type osrmStep struct {
+	Intersections []*osrmIntersection `json:"intersections"`
+}
+
+type osrmIntersection struct {
+	Classes []string `json:"classes"`
+}
+
+// NewOSRMRepository creates a repository that makes requests to the OSRM HTTP
+// API specified in the configuration, using the given HTTP client.
+func NewOSRMRepository(conf *OSRMConfig, client *http.Client) (Repository, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("route.OSRMRepository: missing configuration")
+	}
+
+	if conf.URL == "" {
+		return nil, fmt.Errorf("route.OSRMRepository: missing URL")
+	}
+
+	if conf.Profile == "" {
+		conf.Profile = DefaultOSRMProfile
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("route.OSRMRepository: client is nil")
+	}
+
+	return &OSRMRepository{conf, client}, nil
+}
+
+// GenerateRoute asks the OSRM route service for the route that goes through a
+// trip's stops, and for alternatives when the trip has no intermediate stops.
+// When the trip's stops are to be optimized, the OSRM trip
+// service is used instead, keeping the first and last stops fixed.
+func (or *OSRMRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	coordinates := make([]string, len(t.Stops))
+	for i, s := range t.Stops {
+		if s.Point == nil {
+			return nil, fmt.Errorf("route.OSRMRepository: stop %d has no point", i)
+		}
+		coordinates[i] = fmt.Sprintf("%f,%f", s.Point.Longitude, s.Point.Latitude)
+	}
+
+	service := "route"
+	query := url.Values{}
+	query.Set("overview", "full")
+	query.Set("geometries", "polyline")
+	if t.OptimizeStops {
+		service = "trip"
+		query.Set("source", "first")
+		query.Set("destination", "last")
+		query.Set("roundtrip", "false")
+	} else if len(t.Stops) == 2 {
+		// The steps are needed for the summaries and tolls that tell the
+		// alternatives apart
+		query.Set("alternatives", "true")
+		query.Set("steps", "true")
+	}
+
+	u := fmt.Sprintf(
+		"%s/%s/v1/%s/%s?%s",
+		strings.TrimSuffix(or.conf.URL, "/"),
+		service,
+		url.PathEscape(or.conf.Profile),
+		strings.Join(coordinates, ";"),
+		query.Encode(),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("route.OSRMRepository: failed to create request (%s)", err)
+	}
+
+	resp, err := or.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("route.OSRMRepository: failed to make request (%s)", err)
+	}
+	defer resp.Body.Close()
+
+	var res osrmResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, fmt.Errorf("route.OSRMRepository: failed to decode response with status %d (%s)", resp.StatusCode, err)
+	}
+
+	if res.Code != "Ok" {
+		return nil, fmt.Errorf("route.OSRMRepository: error getting route, %s (%s)", res.Code, res.Message)
+	}
+
+	routes := res.Routes
+	if t.OptimizeStops {
+		routes = res.Trips
+	}
+
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("route.OSRMRepository: no routes found")
+	}
+
+	var order []int
+	if t.OptimizeStops {
+		order, err = osrmOrder(res.Waypoints, len(t.Stops))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	alternatives := make([]*Route, len(routes))
+	for i, r := range routes {
+		alternatives[i] = newRouteFromOSRM(r)
+	}
+
+	route := alternatives[0]
+	route.Order = order
+	route.Alternatives = alternatives[1:]
+
+	return route, nil
+}
+
+func newRouteFromOSRM(r *osrmRoute) *Route {
+	legs := make([]*Leg, len(r.Legs))
+	for i, l := range r.Legs {
+		legs[i] = &Leg{
+			Distance: int(l.Distance),
+			Duration: time.Duration(l.Duration * float64(time.Second)),
+		}
+	}
+
+	summaries := make([]string, 0, len(r.Legs))
+	tolls := false
+	for _, l := range r.Legs {
+		if l.Summary != "" {
+			summaries = append(summaries, l.Summary)
+		}
+
+		for _, s := range l.Steps {
+			for _, i := range s.Intersections {
+				for _, c := range i.Classes {
+					tolls = tolls || c == "toll"
+				}
+			}
+		}
+	}
+
+	return &Route{
+		Legs:     legs,
+		Polyline: r.Geometry,
+		Summary:  strings.Join(summaries, ", "),
+		Tolls:    tolls,
+	}
+}
+
+// osrmOrder converts the position of each stop in the trip returned by the
+// OSRM trip service into the order in which the intermediate stops are
+// visited.
+func osrmOrder(waypoints []*osrmWaypoint, stopsCount int) ([]int, error) {
+	if len(waypoints) != stopsCount {
+		return nil, fmt.Errorf("route.OSRMRepository: expected %d waypoints, got %d", stopsCount, len(waypoints))
+	}
+
+	order := make([]int, stopsCount-2)
+	for i, w := range waypoints[1 : stopsCount-1] {
+		if w == nil || w.WaypointIndex < 1 || w.WaypointIndex > stopsCount-2 {
+			return nil, fmt.Errorf("route.OSRMRepository: invalid waypoint index for stop %d", i+1)
+		}
+		order[w.WaypointIndex-1] = i
+	}
+
+	return order, nil
+}
@@ -0,0 +1,164 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+func newTestOSRMRepository(t *testing.T, handler http.HandlerFunc) Repository {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	repo, err := NewOSRMRepository(&OSRMConfig{URL: server.URL + "/"}, server.Client())
+	if err != nil {
+		t.Fatalf("NewOSRMRepository() error = %v", err)
+	}
+
+	return repo
+}
+
+func newTestTrip(stopsCount int) *entity.Trip {
+	stops := make([]*entity.Stop, stopsCount)
+	for i := range stops {
+		stops[i] = &entity.Stop{
+			Point: &entity.Point{Latitude: 45.5 + float64(i)/10, Longitude: -73.5},
+		}
+	}
+
+	return &entity.Trip{Stops: stops}
+}
+
+func TestOSRMRepositoryGenerateRoute(t *testing.T) {
+	repo := newTestOSRMRepository(t, func(w http.ResponseWriter, r *http.Request) {
+		if want := "/route/v1/driving/-73.500000,45.500000;-73.500000,45.600000"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+
+		query := r.URL.Query()
+		if query.Get("alternatives") != "true" || query.Get("steps") != "true" {
+			t.Errorf("query = %q, want alternatives and steps", r.URL.RawQuery)
+		}
+
+		w.Write([]byte(`{
+			"code": "Ok",
+			"routes": [
+				{
+					"geometry": "_p~iF~ps|U_ulLnnqC",
+					"legs": [{"distance": 12000.4, "duration": 900.5, "summary": "A-15"}]
+				},
+				{
+					"geometry": "_ulLnnqC_mqNvxq",
+					"legs": [{
+						"distance": 15000,
+						"duration": 840,
+						"summary": "A-30",
+						"steps": [{"intersections": [{"classes": ["motorway", "toll"]}]}]
+					}]
+				}
+			]
+		}`))
+	})
+
+	r, err := repo.GenerateRoute(context.Background(), newTestTrip(2))
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	if len(r.Legs) != 1 || r.Legs[0].Distance != 12000 || r.Legs[0].Duration != 900500*time.Millisecond {
+		t.Errorf("legs = %+v, want a leg of 12000 m and 900.5 s", r.Legs[0])
+	}
+
+	if r.Polyline != "_p~iF~ps|U_ulLnnqC" || r.Summary != "A-15" || r.Tolls {
+		t.Errorf("route = %+v, want the first route without tolls", r)
+	}
+
+	if len(r.Alternatives) != 1 {
+		t.Fatalf("len(alternatives) = %d, want 1", len(r.Alternatives))
+	}
+
+	if alt := r.Alternatives[0]; alt.Summary != "A-30" || !alt.Tolls {
+		t.Errorf("alternative = %+v, want the second route with tolls", alt)
+	}
+}
+
+func TestOSRMRepositoryGenerateRouteOptimizeStops(t *testing.T) {
+	repo := newTestOSRMRepository(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/trip/v1/driving/") {
+			t.Errorf("path = %q, want the trip service", r.URL.Path)
+		}
+
+		query := r.URL.Query()
+		if query.Get("source") != "first" || query.Get("destination") != "last" || query.Get("roundtrip") != "false" {
+			t.Errorf("query = %q, want fixed first and last stops", r.URL.RawQuery)
+		}
+
+		w.Write([]byte(`{
+			"code": "Ok",
+			"trips": [{
+				"geometry": "_p~iF~ps|U",
+				"legs": [
+					{"distance": 1000, "duration": 60},
+					{"distance": 2000, "duration": 120},
+					{"distance": 3000, "duration": 180}
+				]
+			}],
+			"waypoints": [
+				{"waypoint_index": 0},
+				{"waypoint_index": 2},
+				{"waypoint_index": 1},
+				{"waypoint_index": 3}
+			]
+		}`))
+	})
+
+	trip := newTestTrip(4)
+	trip.OptimizeStops = true
+
+	r, err := repo.GenerateRoute(context.Background(), trip)
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	if want := []int{1, 0}; !reflect.DeepEqual(r.Order, want) {
+		t.Errorf("order = %v, want %v", r.Order, want)
+	}
+
+	if len(r.Legs) != 3 || len(r.Alternatives) != 0 {
+		t.Errorf("route = %+v, want 3 legs and no alternatives", r)
+	}
+}
+
+func TestOSRMRepositoryGenerateRouteErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"error code", http.StatusBadRequest, `{"code": "NoRoute", "message": "Impossible route between points"}`},
+		{"no routes", http.StatusOK, `{"code": "Ok", "routes": []}`},
+		{"invalid body", http.StatusBadGateway, `<html>Bad Gateway</html>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestOSRMRepository(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := repo.GenerateRoute(context.Background(), newTestTrip(2))
+			if err == nil {
+				t.Fatal("GenerateRoute() error = nil, want an error")
+			}
+		})
+	}
+}
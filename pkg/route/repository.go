@@ -1,10 +1,12 @@
 package route
 
 import (
+	"context"
+
 	"azure.com/ecovo/trip-service/pkg/entity"
 )
 
 // Repository interface
 type Repository interface {
-	GenerateRoute(t *entity.Trip) error
+	GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error)
 }
@@ -0,0 +1,113 @@
+package route
+
+import (
+	"time"
+)
+
+// A Route contains the information computed by a routing provider to drive
+// through a trip's stops, in order.
+type Route struct {
+	// Legs contains the legs between each pair of consecutive stops.
+	Legs []*Leg
+
+	// Polyline contains the route's geometry, in the encoded polyline
+	// algorithm format.
+	Polyline string
+
+	// Order contains the order in which the intermediate stops are visited,
+	// as indices among the intermediate stops, when the routing provider was
+	// asked to optimize it. It is empty when the stops are visited in the
+	// order they were given.
+	Order []int
+
+	// Summary contains a short description of the route given by the
+	// routing provider, such as the name of its main road.
+	Summary string
+
+	// Tolls indicates that the routing provider reported tolls on the route.
+	Tolls bool
+
+	// Alternatives contains the other routes suggested by the routing
+	// provider when it was asked for alternatives, which is only the case for
+	// trips without intermediate stops.
+	Alternatives []*Route
+
+	// Provider contains the name of the routing provider that generated the
+	// route, when it was generated by a FailoverRepository.
+	Provider string
+}
+
+// A Leg contains the information needed to drive between two consecutive
+// stops.
+type Leg struct {
+	// Distance represents the leg's distance in meters.
+	Distance int
+
+	// Duration represents the time it takes to drive the leg.
+	Duration time.Duration
+}
+
+// Distance returns the route's total distance in meters.
+func (r *Route) Distance() int {
+	distance := 0
+	for _, l := range r.Legs {
+		distance += l.Distance
+	}
+
+	return distance
+}
+
+// Duration returns the time it takes to drive the whole route.
+func (r *Route) Duration() time.Duration {
+	var duration time.Duration
+	for _, l := range r.Legs {
+		duration += l.Duration
+	}
+
+	return duration
+}
+
+// copy returns a deep copy of the route, so that it can be modified without
+// affecting the original.
+func (r *Route) copy() *Route {
+	legs := make([]*Leg, len(r.Legs))
+	for i, l := range r.Legs {
+		leg := *l
+		legs[i] = &leg
+	}
+
+	var order []int
+	if r.Order != nil {
+		order = make([]int, len(r.Order))
+		copy(order, r.Order)
+	}
+
+	var alternatives []*Route
+	if r.Alternatives != nil {
+		alternatives = make([]*Route, len(r.Alternatives))
+		for i, a := range r.Alternatives {
+			alternatives[i] = a.copy()
+		}
+	}
+
+	return &Route{
+		Legs:         legs,
+		Polyline:     r.Polyline,
+		Order:        order,
+		Summary:      r.Summary,
+		Tolls:        r.Tolls,
+		Alternatives: alternatives,
+		Provider:     r.Provider,
+	}
+}
+
+// routes returns the route followed by its alternatives, limited to the given
+// number of routes.
+func (r *Route) routes(max int) []*Route {
+	routes := append([]*Route{r}, r.Alternatives...)
+	if len(routes) > max {
+		routes = routes[:max]
+	}
+
+	return routes
+}
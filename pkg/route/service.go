@@ -1,25 +1,220 @@
 package route
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"azure.com/ecovo/trip-service/pkg/entity"
 )
 
 // UseCase interface
 type UseCase interface {
-	CreateRoute(t *entity.Trip) error
+	CreateRoute(ctx context.Context, t *entity.Trip) error
+	EstimateDetour(ctx context.Context, t *entity.Trip, pickup, dropOff *entity.Point) (*entity.Detour, error)
 }
 
 // Service structure
 type Service struct {
-	repo Repository
+	repo      Repository
+	dwellTime time.Duration
+}
+
+const (
+	// DefaultDwellTime represents the time the driver stays at each
+	// intermediate stop to pick up or drop off passengers when none is
+	// configured.
+	DefaultDwellTime = 2 * time.Minute
+
+	// MaxAlternatives represents the maximum number of routes, including
+	// the preferred one, offered to the driver to choose from.
+	MaxAlternatives = 3
+)
+
+// NewService creates the service. The dwell time is the time the driver stays
+// at each intermediate stop.
+func NewService(repo Repository, dwellTime time.Duration) UseCase {
+	return &Service{repo, dwellTime}
+}
+
+// CreateRoute generates route for a trip and updates its departure and arrival
+// times, its stops' arrival and departure times and legs, its total distance
+// and its polyline. When the routing provider suggests alternatives, they are
+// listed on the trip and the one at the trip's route index is used
+func (s *Service) CreateRoute(ctx context.Context, t *entity.Trip) error {
+	if len(t.Stops) < 2 {
+		return fmt.Errorf("route.Service: a trip needs at least 2 stops")
+	}
+
+	if t.LeaveAt.IsZero() && t.ArriveBy.IsZero() {
+		return fmt.Errorf("route.Service: arriveBy OR leaveAt must be specified")
+	}
+
+	r, err := s.repo.GenerateRoute(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	// The alternatives are suggested by the same provider as the route
+	provider := r.Provider
+	routes := r.routes(MaxAlternatives)
+	options := make([]*entity.RouteOption, len(routes))
+	for i, alternative := range routes {
+		options[i] = &entity.RouteOption{
+			Index:    i,
+			Distance: alternative.Distance(),
+			Duration: int(alternative.Duration().Seconds()),
+			Tolls:    alternative.Tolls,
+			Summary:  alternative.Summary,
+			Polyline: alternative.Polyline,
+		}
+	}
+
+	err = t.SelectRoute(options)
+	if err != nil {
+		return err
+	}
+	r = routes[t.RouteIndex]
+	t.RouteProvider = provider
+
+	if len(r.Legs) != len(t.Stops)-1 {
+		return fmt.Errorf("route.Service: expected %d legs in route, got %d", len(t.Stops)-1, len(r.Legs))
+	}
+
+	if t.OptimizeStops && len(r.Order) > 0 {
+		err = reorderStops(t, r.Order)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.TotalDistance = r.Distance()
+
+	duration := r.Duration() + time.Duration(len(t.Stops)-2)*s.dwellTime
+
+	if t.LeaveAt.IsZero() {
+		t.LeaveAt = t.ArriveBy.Add(-duration)
+	}
+
+	if t.ArriveBy.IsZero() {
+		t.ArriveBy = t.LeaveAt.Add(duration)
+	}
+
+	at := t.LeaveAt
+	for i, stop := range t.Stops {
+		if i > 0 {
+			at = at.Add(r.Legs[i-1].Duration)
+		}
+		stop.ArrivalTime = at
+
+		if i > 0 && i < len(t.Stops)-1 {
+			at = at.Add(s.dwellTime)
+		}
+		stop.DepartureTime = at
+
+		if i < len(t.Stops)-1 {
+			stop.TimeStamp = stop.DepartureTime
+		} else {
+			stop.TimeStamp = stop.ArrivalTime
+		}
+
+		if i < len(r.Legs) {
+			stop.Distance = r.Legs[i].Distance
+			stop.Duration = int(r.Legs[i].Duration.Seconds())
+		} else {
+			stop.Distance = 0
+			stop.Duration = 0
+		}
+	}
+
+	t.Polyline = r.Polyline
+
+	return nil
 }
 
-// NewService creates the service
-func NewService(repo Repository) UseCase {
-	return &Service{repo}
+// reorderStops reorders a trip's intermediate stops in the given order, which
+// contains indices among the intermediate stops. The first and last stops are
+// kept in place.
+func reorderStops(t *entity.Trip, order []int) error {
+	intermediates := t.Stops[1 : len(t.Stops)-1]
+	if len(order) != len(intermediates) {
+		return fmt.Errorf("route.Service: expected %d stops in order, got %d", len(intermediates), len(order))
+	}
+
+	reordered := make([]*entity.Stop, len(intermediates))
+	seen := make([]bool, len(intermediates))
+	for i, o := range order {
+		if o < 0 || o >= len(intermediates) || seen[o] {
+			return fmt.Errorf("route.Service: invalid stop order %v", order)
+		}
+		seen[o] = true
+		reordered[i] = intermediates[o]
+	}
+	copy(intermediates, reordered)
+
+	return nil
 }
 
-// CreateRoute generates route for a trip
-func (s *Service) CreateRoute(t *entity.Trip) error {
-	return s.repo.GenerateRoute(t)
+// DetourCandidates represents the number of insertion positions, among the
+// ones adding the shortest great-circle distance, that are routed to find the
+// best one, which bounds the calls made to the routing provider.
+const DetourCandidates = 3
+
+// EstimateDetour computes the extra distance and time it takes to pick up and
+// drop off a passenger at the given points, at the best positions among the
+// trip's stops, and whether it fits within the driver's maximum detour. When
+// no drop-off point is given, only a stop at the pickup point is added.
+func (s *Service) EstimateDetour(ctx context.Context, t *entity.Trip, pickup, dropOff *entity.Point) (*entity.Detour, error) {
+	if len(t.Stops) < 2 {
+		return nil, fmt.Errorf("route.Service: a trip needs at least 2 stops")
+	}
+
+	baseDistance, baseDuration := t.TotalDistance, 0
+	for _, stop := range t.Stops {
+		baseDuration += stop.Duration
+	}
+	if baseDuration == 0 {
+		baseDuration = int((t.ArriveBy.Sub(t.LeaveAt) - time.Duration(len(t.Stops)-2)*s.dwellTime).Seconds())
+	}
+
+	insertions := t.Insertions(pickup, dropOff)
+	if len(insertions) > DetourCandidates {
+		insertions = insertions[:DetourCandidates]
+	}
+
+	insertedStops := 1
+	if dropOff != nil {
+		insertedStops = 2
+	}
+
+	var best *entity.Detour
+	for _, insertion := range insertions {
+		var dropOffStop *entity.Stop
+		if dropOff != nil {
+			dropOffStop = &entity.Stop{Point: dropOff}
+		}
+		candidate := t.WithInsertedStops(&entity.Stop{Point: pickup}, dropOffStop, insertion)
+
+		r, err := s.repo.GenerateRoute(ctx, candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		detour := &entity.Detour{
+			PickupIndex:  insertion.PickupIndex,
+			DropOffIndex: insertion.DropOffIndex,
+			Distance:     r.Distance() - baseDistance,
+			Duration:     int((r.Duration() + time.Duration(insertedStops)*s.dwellTime).Seconds()) - baseDuration,
+		}
+
+		if best == nil || detour.Duration < best.Duration ||
+			(detour.Duration == best.Duration && detour.Distance < best.Distance) {
+			best = detour
+		}
+	}
+
+	best.MaxDistance, best.MaxDuration = t.MaxDetour()
+	best.Acceptable = best.Distance <= best.MaxDistance && best.Duration <= best.MaxDuration
+
+	return best, nil
 }
@@ -0,0 +1,153 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/requestid"
+	"azure.com/ecovo/trip-service/pkg/usage"
+)
+
+// A TrackingRepository is a repository that records each call made to a
+// routing provider, along with the user it is charged to and the request it
+// was made for, and enforces the provider's daily quota of calls.
+type TrackingRepository struct {
+	provider   string
+	repo       Repository
+	usageRepo  usage.Repository
+	dailyQuota int
+}
+
+// A QuotaRepository is a repository that enforces a daily quota of calls to
+// the routing providers for each user.
+type QuotaRepository struct {
+	repo       Repository
+	usageRepo  usage.Repository
+	dailyQuota int
+}
+
+// NewTrackingRepository creates a repository that records the calls made to
+// the provider with the given name through the given repository. A daily
+// quota of 0 means no quota.
+func NewTrackingRepository(provider string, repo Repository, usageRepo usage.Repository, dailyQuota int) (Repository, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("route.TrackingRepository: repository is nil")
+	}
+
+	if usageRepo == nil {
+		return nil, fmt.Errorf("route.TrackingRepository: usage repository is nil")
+	}
+
+	if dailyQuota < 0 {
+		return nil, fmt.Errorf("route.TrackingRepository: daily quota must be at least 0")
+	}
+
+	return &TrackingRepository{provider, repo, usageRepo, dailyQuota}, nil
+}
+
+// GenerateRoute generates the route that goes through a trip's stops with the
+// underlying repository and records the call. A QuotaExceededError is
+// returned without calling the provider when its daily quota is reached, and
+// an UnavailableError when the quota cannot be checked.
+func (tr *TrackingRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	if tr.dailyQuota > 0 {
+		count, err := tr.usageRepo.Count(tr.provider, entity.NilID, "", startOfDay())
+		if err != nil {
+			return nil, UnavailableError{fmt.Sprintf("route.TrackingRepository: failed to check the daily quota of provider \"%s\" (%s)", tr.provider, err)}
+		}
+
+		if count >= tr.dailyQuota {
+			return nil, QuotaExceededError{fmt.Sprintf("route.TrackingRepository: daily quota of %d calls to provider \"%s\" reached", tr.dailyQuota, tr.provider)}
+		}
+	}
+
+	start := time.Now()
+	r, err := tr.repo.GenerateRoute(ctx, t)
+	latency := time.Since(start)
+
+	outcome := entity.RouteUsageOutcomeSuccess
+	if err != nil {
+		outcome = entity.RouteUsageOutcomeFailure
+	}
+
+	// A missing request ID is left empty, such as for calls made outside of
+	// a request
+	requestID, _ := requestid.FromContext(ctx)
+
+	recordErr := tr.usageRepo.Create(&entity.RouteUsage{
+		Provider:  tr.provider,
+		UserID:    chargedUserID(ctx, t),
+		RequestID: requestID,
+		Elements:  len(t.Stops) - 1,
+		Latency:   latency.Milliseconds(),
+		Outcome:   outcome,
+		Timestamp: start,
+	})
+	if recordErr != nil {
+		log.Println(recordErr)
+	}
+
+	return r, err
+}
+
+// NewQuotaRepository creates a repository that enforces the given daily quota
+// of calls to the routing providers for each user.
+func NewQuotaRepository(repo Repository, usageRepo usage.Repository, dailyQuota int) (Repository, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("route.QuotaRepository: repository is nil")
+	}
+
+	if usageRepo == nil {
+		return nil, fmt.Errorf("route.QuotaRepository: usage repository is nil")
+	}
+
+	if dailyQuota <= 0 {
+		return nil, fmt.Errorf("route.QuotaRepository: daily quota must be greater than 0")
+	}
+
+	return &QuotaRepository{repo, usageRepo, dailyQuota}, nil
+}
+
+// GenerateRoute generates the route that goes through a trip's stops with the
+// underlying repository, unless the user the call is charged to has reached
+// their daily quota, in which case a QuotaExceededError is returned. An
+// UnavailableError is returned when the quota cannot be checked.
+//
+// Only the successful calls count towards the quota, so that the calls that
+// failed over to another provider are not counted twice.
+func (qr *QuotaRepository) GenerateRoute(ctx context.Context, t *entity.Trip) (*Route, error) {
+	userID := chargedUserID(ctx, t)
+	if !userID.IsZero() {
+		count, err := qr.usageRepo.Count("", userID, entity.RouteUsageOutcomeSuccess, startOfDay())
+		if err != nil {
+			return nil, UnavailableError{fmt.Sprintf("route.QuotaRepository: failed to check the daily quota of user \"%s\" (%s)", userID, err)}
+		}
+
+		if count >= qr.dailyQuota {
+			return nil, QuotaExceededError{fmt.Sprintf("route.QuotaRepository: daily quota of %d route calculations reached for user \"%s\"", qr.dailyQuota, userID)}
+		}
+	}
+
+	return qr.repo.GenerateRoute(ctx, t)
+}
+
+// chargedUserID returns the ID of the user to whom a call made for a trip is
+// charged, which is the authenticated user who made the request or, for
+// requests made by other services, the trip's driver.
+func chargedUserID(ctx context.Context, t *entity.Trip) entity.ID {
+	userID := usage.UserIDFromContext(ctx)
+	if userID.IsZero() {
+		return t.DriverID
+	}
+
+	return userID
+}
+
+// startOfDay returns the time at which the current day started, in UTC, which
+// is when the daily quotas are reset.
+func startOfDay() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
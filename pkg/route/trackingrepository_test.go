@@ -0,0 +1,276 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/requestid"
+	"azure.com/ecovo/trip-service/pkg/usage"
+)
+
+// A stubUsageRepository is a usage repository that keeps the calls in memory.
+// When it has an error, it fails to count the calls.
+type stubUsageRepository struct {
+	countErr error
+
+	mu     sync.Mutex
+	usages []*entity.RouteUsage
+}
+
+func (s *stubUsageRepository) Create(u *entity.RouteUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.usages = append(s.usages, u)
+
+	return nil
+}
+
+func (s *stubUsageRepository) Count(provider string, userID entity.ID, outcome string, since time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.countErr != nil {
+		return 0, s.countErr
+	}
+
+	count := 0
+	for _, u := range s.usages {
+		if (provider == "" || u.Provider == provider) &&
+			(userID.IsZero() || u.UserID == userID) &&
+			(outcome == "" || u.Outcome == outcome) &&
+			!u.Timestamp.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func newTestTrackedTrip() *entity.Trip {
+	trip := newTestTrip(3)
+	trip.DriverID = "driver"
+
+	return trip
+}
+
+func newTestTrackingRepository(t *testing.T, stub *stubRepository, usageRepo usage.Repository, dailyQuota int) Repository {
+	t.Helper()
+
+	repo, err := NewTrackingRepository(stub.summary, stub, usageRepo, dailyQuota)
+	if err != nil {
+		t.Fatalf("NewTrackingRepository() error = %v", err)
+	}
+
+	return repo
+}
+
+func TestTrackingRepositoryRecords(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		errs        []error
+		wantUserID  entity.ID
+		wantOutcome string
+	}{
+		{
+			"user request",
+			usage.NewContext(context.Background(), "auth0|passenger"),
+			nil,
+			"auth0|passenger",
+			entity.RouteUsageOutcomeSuccess,
+		},
+		{
+			"service request",
+			context.Background(),
+			nil,
+			"driver",
+			entity.RouteUsageOutcomeSuccess,
+		},
+		{
+			"failed call",
+			usage.NewContext(context.Background(), "auth0|passenger"),
+			[]error{errors.New("unavailable")},
+			"auth0|passenger",
+			entity.RouteUsageOutcomeFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usageRepo := &stubUsageRepository{}
+			repo := newTestTrackingRepository(t, &stubRepository{summary: "osrm", errs: tt.errs}, usageRepo, 0)
+
+			ctx := context.WithValue(tt.ctx, requestid.RequestIDContextKey, "request")
+			repo.GenerateRoute(ctx, newTestTrackedTrip())
+
+			if len(usageRepo.usages) != 1 {
+				t.Fatalf("len(usages) = %d, want 1", len(usageRepo.usages))
+			}
+
+			u := usageRepo.usages[0]
+			if u.Provider != "osrm" || u.UserID != tt.wantUserID || u.RequestID != "request" || u.Elements != 2 || u.Outcome != tt.wantOutcome {
+				t.Errorf("usage = %+v, want a call to osrm charged to %s with 2 elements and a %s", u, tt.wantUserID, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func TestTrackingRepositoryQuota(t *testing.T) {
+	tests := []struct {
+		name      string
+		usageRepo *stubUsageRepository
+		wantErr   error
+	}{
+		{
+			"quota not reached",
+			&stubUsageRepository{usages: []*entity.RouteUsage{{Provider: "osrm", Timestamp: time.Now()}}},
+			nil,
+		},
+		{
+			"quota reached",
+			&stubUsageRepository{usages: []*entity.RouteUsage{
+				{Provider: "osrm", Outcome: entity.RouteUsageOutcomeFailure, Timestamp: time.Now()},
+				{Provider: "osrm", Outcome: entity.RouteUsageOutcomeSuccess, Timestamp: time.Now()},
+			}},
+			QuotaExceededError{},
+		},
+		{
+			"calls of other providers and days",
+			&stubUsageRepository{usages: []*entity.RouteUsage{
+				{Provider: "google", Timestamp: time.Now()},
+				{Provider: "osrm", Timestamp: startOfDay().Add(-time.Second)},
+			}},
+			nil,
+		},
+		{
+			"quota cannot be checked",
+			&stubUsageRepository{countErr: errors.New("database unavailable")},
+			UnavailableError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubRepository{summary: "osrm"}
+			repo := newTestTrackingRepository(t, stub, tt.usageRepo, 2)
+
+			_, err := repo.GenerateRoute(context.Background(), newTestTrackedTrip())
+
+			wantCalls := 1
+			switch tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("GenerateRoute() error = %v", err)
+				}
+			case QuotaExceededError:
+				if _, ok := err.(QuotaExceededError); !ok {
+					t.Fatalf("GenerateRoute() error = %v, want a QuotaExceededError", err)
+				}
+				wantCalls = 0
+			case UnavailableError:
+				if _, ok := err.(UnavailableError); !ok {
+					t.Fatalf("GenerateRoute() error = %v, want an UnavailableError", err)
+				}
+				wantCalls = 0
+			}
+
+			if stub.callCount() != wantCalls {
+				t.Errorf("calls = %d, want %d", stub.callCount(), wantCalls)
+			}
+		})
+	}
+}
+
+// newTestQuotaRepository creates a repository that enforces the given daily
+// quota for each user on top of two tracked providers, the first of which
+// fails the given number of times.
+func newTestQuotaRepository(t *testing.T, usageRepo usage.Repository, dailyQuota int, failures int) Repository {
+	t.Helper()
+
+	errs := make([]error, failures)
+	for i := range errs {
+		errs[i] = errors.New("unavailable")
+	}
+
+	stubs := []*stubRepository{{summary: "google", errs: errs}, {summary: "osrm"}}
+	providers := make([]*Provider, len(stubs))
+	for i, stub := range stubs {
+		providers[i] = &Provider{stub.summary, newTestTrackingRepository(t, stub, usageRepo, 0)}
+	}
+
+	failover, err := NewFailoverRepository(providers, &FailoverConfig{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewFailoverRepository() error = %v", err)
+	}
+
+	repo, err := NewQuotaRepository(failover, usageRepo, dailyQuota)
+	if err != nil {
+		t.Fatalf("NewQuotaRepository() error = %v", err)
+	}
+
+	return repo
+}
+
+func TestQuotaRepositoryChargesSuccessfulCalls(t *testing.T) {
+	usageRepo := &stubUsageRepository{}
+	repo := newTestQuotaRepository(t, usageRepo, 2, 2)
+	ctx := usage.NewContext(context.Background(), "auth0|passenger")
+
+	// The first two calls fail over to the second provider, which makes two
+	// records each but only charges the user once per call
+	for i := 0; i < 2; i++ {
+		_, err := repo.GenerateRoute(ctx, newTestTrackedTrip())
+		if err != nil {
+			t.Fatalf("GenerateRoute() #%d error = %v", i+1, err)
+		}
+	}
+
+	if len(usageRepo.usages) != 4 {
+		t.Errorf("len(usages) = %d, want 4", len(usageRepo.usages))
+	}
+
+	_, err := repo.GenerateRoute(ctx, newTestTrackedTrip())
+	if _, ok := err.(QuotaExceededError); !ok {
+		t.Fatalf("GenerateRoute() error = %v, want a QuotaExceededError", err)
+	}
+}
+
+func TestQuotaRepositoryChargesCaller(t *testing.T) {
+	usageRepo := &stubUsageRepository{}
+	repo := newTestQuotaRepository(t, usageRepo, 1, 0)
+
+	// A passenger estimating a detour does not use the driver's quota
+	_, err := repo.GenerateRoute(usage.NewContext(context.Background(), "auth0|passenger"), newTestTrackedTrip())
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+
+	_, err = repo.GenerateRoute(context.Background(), newTestTrackedTrip())
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v, want the driver's quota to be left", err)
+	}
+
+	_, err = repo.GenerateRoute(usage.NewContext(context.Background(), "auth0|passenger"), newTestTrackedTrip())
+	if _, ok := err.(QuotaExceededError); !ok {
+		t.Fatalf("GenerateRoute() error = %v, want a QuotaExceededError", err)
+	}
+}
+
+func TestQuotaRepositoryUnavailable(t *testing.T) {
+	usageRepo := &stubUsageRepository{countErr: errors.New("database unavailable")}
+	repo := newTestQuotaRepository(t, usageRepo, 1, 0)
+
+	_, err := repo.GenerateRoute(context.Background(), newTestTrackedTrip())
+	if _, ok := err.(UnavailableError); !ok {
+		t.Fatalf("GenerateRoute() error = %v, want an UnavailableError", err)
+	}
+
+	if len(usageRepo.usages) != 0 {
+		t.Errorf("len(usages) = %d, want 0 since no provider was called", len(usageRepo.usages))
+	}
+}
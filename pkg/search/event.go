@@ -0,0 +1,7 @@
+package search
+
+const (
+	// EventTripMatched represents the event where a new trip matches a saved
+	// search.
+	EventTripMatched = "TRIP_MATCHED"
+)
@@ -0,0 +1,230 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// A MongoRepository is a repository that performs CRUD operations on saved
+// searches in a MongoDB collection.
+type MongoRepository struct {
+	collection *mongo.Collection
+}
+
+type document struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	UserID  primitive.ObjectID `bson:"userId"`
+	Filters *entity.Filters    `bson:"filters"`
+}
+
+func newDocumentFromEntity(s *entity.Search) (*document, error) {
+	if s == nil {
+		return nil, fmt.Errorf("search.MongoRepository: entity is nil")
+	}
+
+	ID, err := getObjectID(s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := getObjectID(s.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &document{
+		ID,
+		userID,
+		s.Filters,
+	}, nil
+}
+
+func (d document) Entity() *entity.Search {
+	return &entity.Search{
+		entity.NewIDFromHex(d.ID.Hex()),
+		entity.NewIDFromHex(d.UserID.Hex()),
+		d.Filters,
+	}
+}
+
+// NewMongoRepository creates a search repository for a MongoDB collection.
+func NewMongoRepository(collection *mongo.Collection) (Repository, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("search.MongoRepository: collection is nil")
+	}
+
+	return &MongoRepository{collection}, nil
+}
+
+// FindByID retrieves the search with the given ID, if it exists.
+func (r *MongoRepository) FindByID(ID entity.ID) (*entity.Search, error) {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("search.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{"_id", objectID}}
+	var d document
+	err = r.collection.FindOne(context.TODO(), filter).Decode(&d)
+	if err != nil {
+		return nil, fmt.Errorf("search.MongoRepository: no search found with ID \"%s\" (%s)", ID, err)
+	}
+
+	return d.Entity(), nil
+}
+
+// FindByUserID retrieves the searches saved by the user with the given ID.
+func (r *MongoRepository) FindByUserID(userID entity.ID) ([]*entity.Search, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("search.MongoRepository: failed to create object ID")
+	}
+
+	return r.find(bson.D{{"userId", objectID}})
+}
+
+// FindByTrip retrieves the saved searches that the trip could match. Only the
+// criteria that can be evaluated by the database are used, such as the driver,
+// the seats, the trip's details, the vehicle's year and features and the time
+// window, so the searches must still be matched against the trip.
+func (r *MongoRepository) FindByTrip(t *entity.Trip) ([]*entity.Search, error) {
+	if t == nil || len(t.Stops) < 2 {
+		return nil, fmt.Errorf("search.MongoRepository: trip has no stops")
+	}
+
+	animals := bson.A{nil}
+	var luggages interface{}
+	if t.Details != nil {
+		animals = append(animals, t.Details.Animals)
+		luggages = t.Details.Luggages
+	}
+
+	var year interface{}
+	features := []string{}
+	if t.Vehicle != nil {
+		year = t.Vehicle.Year
+		features = append(features, t.Vehicle.Features...)
+	}
+
+	departure := t.Stops[0].Departure()
+	arrival := t.Stops[len(t.Stops)-1].Arrival()
+	threshold := time.Hour * entity.TimeThreshold
+
+	filter := bson.D{
+		{"filters.driverid", bson.D{{"$in", bson.A{nil, "", t.DriverID.Hex()}}}},
+		{"filters.seats", atMost(t.Seats)},
+		{"filters.detailsanimals", bson.D{{"$in", animals}}},
+		{"filters.detailsluggages", atMost(luggages)},
+		{"filters.vehicleminyear", atMost(year)},
+		{"filters.vehiclefeatures", bson.D{{"$not", bson.D{{"$elemMatch", bson.D{{"$nin", features}}}}}}},
+		{"$and", bson.A{
+			withinTimeWindow("filters.leaveat", departure.Add(-threshold), arrival.Add(threshold)),
+			withinTimeWindow("filters.arriveby", departure.Add(-threshold), arrival.Add(threshold)),
+		}},
+	}
+
+	return r.find(filter)
+}
+
+// atMost matches a field that is missing, null or lower than or equal to the
+// given value. When the value is nil, only a missing or null field matches.
+func atMost(value interface{}) bson.D {
+	if value == nil {
+		return bson.D{{"$in", bson.A{nil}}}
+	}
+
+	return bson.D{{"$not", bson.D{{"$gt", value}}}}
+}
+
+// withinTimeWindow matches a time field that is not set or that is between the
+// given times.
+func withinTimeWindow(field string, from, to time.Time) bson.D {
+	return bson.D{{"$or", bson.A{
+		bson.D{{field, bson.D{{"$in", bson.A{nil, time.Time{}}}}}},
+		bson.D{{field, bson.D{{"$gt", from}, {"$lt", to}}}},
+	}}}
+}
+
+func (r *MongoRepository) find(filter bson.D) ([]*entity.Search, error) {
+	cur, err := r.collection.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("search.MongoRepository: no search found (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	searches := make([]*entity.Search, 0)
+	for cur.Next(context.TODO()) {
+		var d document
+		err := cur.Decode(&d)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, d.Entity())
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+// Create stores the new search in the database and returns the unique
+// identifier that was generated for it.
+func (r *MongoRepository) Create(s *entity.Search) (entity.ID, error) {
+	if s == nil {
+		return entity.NilID, fmt.Errorf("search.MongoRepository: failed to create search (search is nil)")
+	}
+
+	d, err := newDocumentFromEntity(s)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("search.MongoRepository: failed to create search document from entity (%s)", err)
+	}
+
+	res, err := r.collection.InsertOne(context.TODO(), d)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("search.MongoRepository: failed to create search (%s)", err)
+	}
+
+	ID, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return entity.NilID, fmt.Errorf("search.MongoRepository: failed to get ID of created search")
+	}
+
+	return entity.ID(ID.Hex()), nil
+}
+
+// Delete removes the search with the given ID from the database.
+func (r *MongoRepository) Delete(ID entity.ID) error {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return fmt.Errorf("search.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{"_id", objectID}}
+	_, err = r.collection.DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("search.MongoRepository: failed to delete search with ID \"%s\" (%s)", ID, err)
+	}
+
+	return nil
+}
+
+// Gets an object ID from an entity of type ID
+func getObjectID(rawID entity.ID) (primitive.ObjectID, error) {
+	if rawID.IsZero() {
+		return primitive.NilObjectID, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(rawID.Hex())
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("search.MongoRepository: failed to create object")
+	}
+	return objectID, nil
+}
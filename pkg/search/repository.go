@@ -0,0 +1,15 @@
+package search
+
+import (
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// Repository is an interface representing the ability to perform CRUD
+// operations on saved searches in a database.
+type Repository interface {
+	FindByID(ID entity.ID) (*entity.Search, error)
+	FindByUserID(userID entity.ID) ([]*entity.Search, error)
+	FindByTrip(t *entity.Trip) ([]*entity.Search, error)
+	Create(search *entity.Search) (entity.ID, error)
+	Delete(ID entity.ID) error
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"fmt"
+	"log"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/pubsub"
+	"azure.com/ecovo/trip-service/pkg/pubsub/subscription"
+)
+
+// UseCase is an interface representing the ability to handle the business
+// logic that involves saved searches.
+type UseCase interface {
+	Register(s *entity.Search) (*entity.Search, error)
+	FindByID(ID entity.ID) (*entity.Search, error)
+	FindByUserID(userID entity.ID) ([]*entity.Search, error)
+	Delete(ID entity.ID) error
+	NotifyTripAdded(t *entity.Trip) error
+}
+
+// A Service handles the business logic related to saved searches.
+type Service struct {
+	repo          Repository
+	pubSubService pubsub.UseCase
+}
+
+// A Notification is the content of the message published to a user when a
+// new trip matches one of their saved searches.
+type Notification struct {
+	SearchID entity.ID    `json:"searchId"`
+	Trip     *entity.Trip `json:"trip"`
+}
+
+// NewService creates a search service to handle business logic and manipulate
+// saved searches through a repository.
+func NewService(repo Repository, pubSubService pubsub.UseCase) *Service {
+	return &Service{repo, pubSubService}
+}
+
+// Register validates the search's information and saves it.
+func (s *Service) Register(search *entity.Search) (*entity.Search, error) {
+	if search == nil {
+		return nil, fmt.Errorf("search.Service: search is nil")
+	}
+
+	err := search.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	search.ID, err = s.repo.Create(search)
+	if err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
+
+// FindByID retrieves the search with the given ID in the repository, if it
+// exists.
+func (s *Service) FindByID(ID entity.ID) (*entity.Search, error) {
+	search, err := s.repo.FindByID(ID)
+	if err != nil {
+		return nil, NotFoundError{err.Error()}
+	}
+
+	return search, nil
+}
+
+// FindByUserID retrieves the searches saved by the user with the given ID.
+func (s *Service) FindByUserID(userID entity.ID) ([]*entity.Search, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+// Delete erases the search from the repository.
+func (s *Service) Delete(ID entity.ID) error {
+	return s.repo.Delete(ID)
+}
+
+// NotifyTripAdded evaluates a new trip against the saved searches it could
+// match, and notifies the users whose searches it matches on their channel.
+func (s *Service) NotifyTripAdded(t *entity.Trip) error {
+	if t == nil {
+		return fmt.Errorf("search.Service: trip is nil")
+	}
+
+	searches, err := s.repo.FindByTrip(t)
+	if err != nil {
+		return err
+	}
+
+	for _, search := range searches {
+		if search.Filters == nil {
+			continue
+		}
+
+		m := search.Filters.Match(t)
+		if m == nil {
+			continue
+		}
+
+		matchedTrip := *t
+		matchedTrip.Match = m
+
+		err := s.notify(search.UserID, &Notification{search.ID, &matchedTrip})
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) notify(userID entity.ID, n *Notification) error {
+	return s.pubSubService.Publish(pubsub.UserTopic(userID.Hex()), &subscription.Message{
+		Type: EventTripMatched,
+		Data: n,
+	})
+}
@@ -6,4 +6,20 @@ const (
 
 	// EventTripAdded represents the event where a trip has been added.
 	EventTripAdded = "TRIP_ADDED"
+
+	// EventProposalAdded represents the event where a passenger has proposed
+	// a stop for a trip, published to its driver.
+	EventProposalAdded = "PROPOSAL_ADDED"
+
+	// EventProposalAccepted represents the event where a driver has accepted
+	// a proposed stop, published to the passenger who proposed it.
+	EventProposalAccepted = "PROPOSAL_ACCEPTED"
+
+	// EventProposalRejected represents the event where a driver has rejected
+	// a proposed stop, published to the passenger who proposed it.
+	EventProposalRejected = "PROPOSAL_REJECTED"
+
+	// EventTripRescheduled represents the event where the times a passenger
+	// is picked up or dropped off have changed, published to the passenger.
+	EventTripRescheduled = "TRIP_RESCHEDULED"
 )
@@ -3,6 +3,7 @@ package trip
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
 	"azure.com/ecovo/trip-service/pkg/entity"
@@ -14,10 +15,12 @@ import (
 
 const (
 	// DefaultRadius represents the default radius for a location search.
-	DefaultRadius = 2000
+	DefaultRadius = entity.DefaultRadiusThresh
 
 	// TimeThreshold represents the time threshold for leaveAt or arriveBy (in hours)
-	TimeThreshold = 12
+	TimeThreshold = entity.TimeThreshold
+
+	stopsTextIndexName = "stops_point_name_text"
 )
 
 // A MongoRepository is a repository that performs CRUD operations on trips in
@@ -27,26 +30,50 @@ type MongoRepository struct {
 }
 
 type document struct {
-	ID                primitive.ObjectID `bson:"_id,omitempty"`
-	DriverID          primitive.ObjectID `bson:"driverId"`
-	Vehicle           *entity.Vehicle    `bson:"vehicle"`
-	Full              bool               `bson:"full"`
-	LeaveAt           time.Time          `bson:"leaveAt"`
-	ArriveBy          time.Time          `bson:"arriveBy"`
-	Seats             int                `bson:"seats"`
-	Stops             []*stop            `bson:"stops"`
-	Details           *entity.Details    `bson:"details"`
-	ReservationsCount int                `bson:"reservationsCount"`
-	TotalTripPrice    float64            `bson:"totalTripPrice"`
-	PricePerSeat      float64            `bson:"pricePerSeat"`
-	TotalDistance     int                `bson:"totalDistance"`
+	ID                    primitive.ObjectID     `bson:"_id,omitempty"`
+	DriverID              primitive.ObjectID     `bson:"driverId"`
+	Vehicle               *entity.Vehicle        `bson:"vehicle"`
+	Full                  bool                   `bson:"full"`
+	LeaveAt               time.Time              `bson:"leaveAt"`
+	ArriveBy              time.Time              `bson:"arriveBy"`
+	Seats                 int                    `bson:"seats"`
+	Stops                 []*stop                `bson:"stops"`
+	Details               *entity.Details        `bson:"details"`
+	ReservationsCount     int                    `bson:"reservationsCount"`
+	TotalTripPrice        float64                `bson:"totalTripPrice"`
+	PricePerSeat          float64                `bson:"pricePerSeat"`
+	Costs                 *entity.Costs          `bson:"costs"`
+	PriceBreakdown        *entity.PriceBreakdown `bson:"priceBreakdown"`
+	PricePerSeatBreakdown *entity.PriceBreakdown `bson:"pricePerSeatBreakdown"`
+	TotalDistance         int                    `bson:"totalDistance"`
+	Polyline              string                 `bson:"polyline"`
+	OptimizeStops         bool                   `bson:"optimizeStops"`
+	Emissions             float64                `bson:"emissions"`
+	MaxDetourDistance     int                    `bson:"maxDetourDistance"`
+	MaxDetourDuration     int                    `bson:"maxDetourDuration"`
+	Proposals             []*proposal            `bson:"proposals"`
+	RouteIndex            int                    `bson:"routeIndex"`
+	RouteSummary          string                 `bson:"routeSummary"`
+	RouteProvider         string                 `bson:"routeProvider"`
 }
 
 type stop struct {
-	ID        primitive.ObjectID `bson:"id"`
-	Point     *entity.Point      `bson:"point"`
-	Seats     int                `bson:"seats"`
-	TimeStamp time.Time          `bson:"timestamp"`
+	ID            primitive.ObjectID `bson:"id"`
+	Point         *entity.Point      `bson:"point"`
+	Seats         int                `bson:"seats"`
+	TimeStamp     time.Time          `bson:"timestamp"`
+	ArrivalTime   time.Time          `bson:"arrivalTime"`
+	DepartureTime time.Time          `bson:"departureTime"`
+	Distance      int                `bson:"distance"`
+	Duration      int                `bson:"duration"`
+}
+
+type proposal struct {
+	ID     primitive.ObjectID `bson:"id"`
+	UserID primitive.ObjectID `bson:"userId"`
+	Point  *entity.Point      `bson:"point"`
+	Status string             `bson:"status"`
+	StopID primitive.ObjectID `bson:"stopId"`
 }
 
 func newDocumentFromEntity(t *entity.Trip) (*document, error) {
@@ -76,6 +103,30 @@ func newDocumentFromEntity(t *entity.Trip) (*document, error) {
 			s.Point,
 			s.Seats,
 			s.TimeStamp,
+			s.ArrivalTime,
+			s.DepartureTime,
+			s.Distance,
+			s.Duration,
+		}
+	}
+
+	proposals := make([]*proposal, len(t.Proposals))
+	for i, p := range t.Proposals {
+		IDs := make([]primitive.ObjectID, 3)
+		for j, rawID := range []entity.ID{p.ID, p.UserID, p.StopID} {
+			ID, err := getObjectID(rawID)
+			if err != nil {
+				return nil, err
+			}
+			IDs[j] = ID
+		}
+
+		proposals[i] = &proposal{
+			IDs[0],
+			IDs[1],
+			p.Point,
+			p.Status,
+			IDs[2],
 		}
 	}
 
@@ -92,7 +143,19 @@ func newDocumentFromEntity(t *entity.Trip) (*document, error) {
 		t.ReservationsCount,
 		t.TotalTripPrice,
 		t.PricePerSeat,
+		t.Costs,
+		t.PriceBreakdown,
+		t.PricePerSeatBreakdown,
 		t.TotalDistance,
+		t.Polyline,
+		t.OptimizeStops,
+		t.Emissions,
+		t.MaxDetourDistance,
+		t.MaxDetourDuration,
+		proposals,
+		t.RouteIndex,
+		t.RouteSummary,
+		t.RouteProvider,
 	}, nil
 }
 
@@ -104,6 +167,26 @@ func (d document) Entity() *entity.Trip {
 			s.Point,
 			s.Seats,
 			s.TimeStamp,
+			s.ArrivalTime,
+			s.DepartureTime,
+			s.Distance,
+			s.Duration,
+		}
+	}
+
+	proposals := make([]*entity.Proposal, len(d.Proposals))
+	for i, p := range d.Proposals {
+		var stopID entity.ID
+		if !p.StopID.IsZero() {
+			stopID = entity.NewIDFromHex(p.StopID.Hex())
+		}
+
+		proposals[i] = &entity.Proposal{
+			entity.NewIDFromHex(p.ID.Hex()),
+			entity.NewIDFromHex(p.UserID.Hex()),
+			p.Point,
+			p.Status,
+			stopID,
 		}
 	}
 
@@ -120,7 +203,21 @@ func (d document) Entity() *entity.Trip {
 		d.ReservationsCount,
 		d.TotalTripPrice,
 		d.PricePerSeat,
+		d.Costs,
+		d.PriceBreakdown,
+		d.PricePerSeatBreakdown,
 		d.TotalDistance,
+		d.Polyline,
+		d.OptimizeStops,
+		d.Emissions,
+		d.MaxDetourDistance,
+		d.MaxDetourDuration,
+		proposals,
+		d.RouteIndex,
+		d.RouteSummary,
+		nil,
+		d.RouteProvider,
+		nil,
 	}
 }
 
@@ -130,9 +227,33 @@ func NewMongoRepository(collection *mongo.Collection) (Repository, error) {
 		return nil, fmt.Errorf("trip.MongoRepository: collection is nil")
 	}
 
+	err := createIndexes(collection)
+	if err != nil {
+		return nil, err
+	}
+
 	return &MongoRepository{collection}, nil
 }
 
+// Creates the indexes required to search trips, if they do not exist.
+//
+// The text index over the stops' names does not use a language, so that
+// place names are not stemmed. Text indexes are case and diacritic
+// insensitive.
+func createIndexes(collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{"stops.point.name", "text"}},
+		Options: options.Index().
+			SetName(stopsTextIndexName).
+			SetDefaultLanguage("none"),
+	})
+	if err != nil {
+		return fmt.Errorf("trip.MongoRepository: failed to create text index (%s)", err)
+	}
+
+	return nil
+}
+
 // FindByID retrieves the trip with the given ID, if it exists.
 func (r *MongoRepository) FindByID(ID entity.ID) (*entity.Trip, error) {
 	objectID, err := primitive.ObjectIDFromHex(string(ID))
@@ -211,8 +332,21 @@ func (r *MongoRepository) Create(t *entity.Trip) (entity.ID, error) {
 	return entity.ID(ID.Hex()), nil
 }
 
-// Update updates the trip in the database.
+// Update updates the trip in the database. The stops and proposals that were
+// added to the trip are given an ID.
 func (r *MongoRepository) Update(t *entity.Trip) error {
+	for _, s := range t.Stops {
+		if s.ID.IsZero() {
+			s.ID = entity.ID(primitive.NewObjectID().Hex())
+		}
+	}
+
+	for _, p := range t.Proposals {
+		if p.ID.IsZero() {
+			p.ID = entity.ID(primitive.NewObjectID().Hex())
+		}
+	}
+
 	d, err := newDocumentFromEntity(t)
 	if err != nil {
 		return fmt.Errorf("trip.MongoRepository: failed to create trip document from entity (%s)", err)
@@ -256,6 +390,16 @@ func newDocumentFromFilters(f *entity.Filters) (bson.D, error) {
 
 	d = append(d, bson.E{"full", false})
 
+	// The text index covers every stop, so the trips are filtered on the names
+	// of their source and destination once retrieved.
+	if f.Query != "" {
+		d = append(d, bson.E{
+			"$text", bson.M{
+				"$search": f.Query,
+			},
+		})
+	}
+
 	if f.DriverID != "" {
 		objectID, err := primitive.ObjectIDFromHex(f.DriverID)
 		if err != nil {
@@ -285,24 +429,45 @@ func newDocumentFromFilters(f *entity.Filters) (bson.D, error) {
 		})
 	}
 
-	if !f.LeaveAt.IsZero() {
+	if f.VehicleMake != "" {
+		d = append(d, bson.E{"vehicle.make", newCaseInsensitiveRegex(f.VehicleMake)})
+	}
+
+	if f.VehicleModel != "" {
+		d = append(d, bson.E{"vehicle.model", newCaseInsensitiveRegex(f.VehicleModel)})
+	}
+
+	if f.VehicleMinYear != nil {
 		d = append(d, bson.E{
-			"leaveAt", bson.M{
-				"$gt": f.LeaveAt.Add(time.Hour * (-TimeThreshold)),
-				"$lt": f.LeaveAt.Add(time.Hour * TimeThreshold),
+			"vehicle.year", bson.M{
+				"$gte": *f.VehicleMinYear,
 			},
 		})
 	}
 
-	if !f.ArriveBy.IsZero() {
+	if len(f.VehicleFeatures) > 0 {
 		d = append(d, bson.E{
-			"arriveBy", bson.M{
-				"$gt": f.ArriveBy.Add(time.Hour * (-TimeThreshold)),
-				"$lt": f.ArriveBy.Add(time.Hour * TimeThreshold),
+			"vehicle.features", bson.M{
+				"$all": f.VehicleFeatures,
 			},
 		})
 	}
 
+	// The passenger is picked up or dropped off between the trip's departure
+	// and arrival, so only the trips that overlap the requested time window
+	// can match
+	requested := f.LeaveAt
+	if requested.IsZero() {
+		requested = f.ArriveBy
+	}
+
+	if !requested.IsZero() {
+		d = append(d,
+			bson.E{"leaveAt", bson.M{"$lt": requested.Add(time.Hour * TimeThreshold)}},
+			bson.E{"arriveBy", bson.M{"$gt": requested.Add(time.Hour * (-TimeThreshold))}},
+		)
+	}
+
 	// radiusThresh := 0
 	// if f.RadiusThresh != nil {
 	// 	radiusThresh = *f.RadiusThresh
@@ -327,6 +492,12 @@ func newDocumentFromFilters(f *entity.Filters) (bson.D, error) {
 	return d, nil
 }
 
+// Creates a regular expression that matches the whole value, regardless of
+// its case
+func newCaseInsensitiveRegex(value string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"}
+}
+
 // Gets an object ID from an entity of type ID
 func getObjectID(rawID entity.ID) (primitive.ObjectID, error) {
 	if rawID.IsZero() {
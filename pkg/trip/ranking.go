@@ -0,0 +1,161 @@
+package trip
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// RankingWeights contains the weight given to each component of a search
+// result's score. The weights are relative to each other.
+type RankingWeights struct {
+	WalkingDistance float64
+	TimeDifference  float64
+	PricePerSeat    float64
+	RemainingSeats  float64
+}
+
+// DefaultRankingWeights represents the weights used to rank search results
+// when none are configured.
+var DefaultRankingWeights = RankingWeights{
+	WalkingDistance: 4,
+	TimeDifference:  3,
+	PricePerSeat:    2,
+	RemainingSeats:  1,
+}
+
+const (
+	// WalkingDistanceScale represents the walking distance (in meters) at
+	// which the walking distance component of a score is halved.
+	WalkingDistanceScale = 1000.0
+
+	// TimeDifferenceScale represents the time difference at which the time
+	// difference component of a score is halved.
+	TimeDifferenceScale = 30 * time.Minute
+
+	// PricePerSeatScale represents the price per seat (in dollars) at which
+	// the price component of a score is halved.
+	PricePerSeatScale = 10.0
+)
+
+// A Ranker sorts search results by relevance.
+type Ranker struct {
+	weights RankingWeights
+}
+
+// NewRanker creates a ranker that scores search results using the given
+// weights.
+func NewRanker(weights *RankingWeights) (*Ranker, error) {
+	if weights == nil {
+		return nil, fmt.Errorf("trip.Ranker: weights are nil")
+	}
+
+	if weights.WalkingDistance < 0 || weights.TimeDifference < 0 || weights.PricePerSeat < 0 || weights.RemainingSeats < 0 {
+		return nil, fmt.Errorf("trip.Ranker: weights must be positive")
+	}
+
+	return &Ranker{*weights}, nil
+}
+
+// Rank scores the matched trips against the filters they were found with and
+// sorts them from most to least relevant. The trips must have been matched
+// against the filters beforehand.
+func (r *Ranker) Rank(trips []*entity.Trip, f *entity.Filters) {
+	for _, t := range trips {
+		if t.Match != nil {
+			t.Match.Score = r.score(t, f)
+		}
+	}
+
+	sort.SliceStable(trips, func(i, j int) bool {
+		return scoreValue(trips[i]) > scoreValue(trips[j])
+	})
+}
+
+func (r *Ranker) score(t *entity.Trip, f *entity.Filters) *entity.Score {
+	pickup := t.IndexOfStop(t.Match.PickupID)
+	dropOff := t.IndexOfStop(t.Match.DropOffID)
+
+	seats := entity.MinimumSeats
+	if f.Seats != nil && *f.Seats > seats {
+		seats = *f.Seats
+	}
+
+	var timeDifference time.Duration
+	if !f.LeaveAt.IsZero() && pickup >= 0 {
+		timeDifference = t.Stops[pickup].Departure().Sub(f.LeaveAt)
+	} else if !f.ArriveBy.IsZero() && dropOff >= 0 {
+		timeDifference = t.Stops[dropOff].Arrival().Sub(f.ArriveBy)
+	}
+	if timeDifference < 0 {
+		timeDifference = -timeDifference
+	}
+
+	walkingDistance := t.Match.PickupDistance + t.Match.DropOffDistance
+	pricePerSeat := t.FareFor(seats) / float64(seats)
+	remainingSeats := t.Seats
+	if pickup >= 0 && dropOff >= 0 {
+		remainingSeats = t.AvailableSeats(pickup, dropOff)
+	}
+
+	remainingSeatsValue := 0.0
+	if t.Seats > 0 {
+		remainingSeatsValue = math.Min(float64(remainingSeats)/float64(t.Seats), 1)
+	}
+
+	s := &entity.Score{
+		WalkingDistance: &entity.ScoreComponent{
+			Measure: walkingDistance,
+			Value:   decay(walkingDistance, WalkingDistanceScale),
+			Weight:  r.weights.WalkingDistance,
+		},
+		TimeDifference: &entity.ScoreComponent{
+			Measure: timeDifference.Minutes(),
+			Value:   decay(timeDifference.Minutes(), TimeDifferenceScale.Minutes()),
+			Weight:  r.weights.TimeDifference,
+		},
+		PricePerSeat: &entity.ScoreComponent{
+			Measure: pricePerSeat,
+			Value:   decay(pricePerSeat, PricePerSeatScale),
+			Weight:  r.weights.PricePerSeat,
+		},
+		RemainingSeats: &entity.ScoreComponent{
+			Measure: float64(remainingSeats),
+			Value:   remainingSeatsValue,
+			Weight:  r.weights.RemainingSeats,
+		},
+	}
+
+	totalWeight := 0.0
+	for _, c := range []*entity.ScoreComponent{s.WalkingDistance, s.TimeDifference, s.PricePerSeat, s.RemainingSeats} {
+		s.Value += c.Value * c.Weight
+		totalWeight += c.Weight
+	}
+	if totalWeight > 0 {
+		s.Value /= totalWeight
+	}
+
+	return s
+}
+
+// decay normalizes a measure where smaller is better to a value between 0 and
+// 1, where 1 is the best possible value and a measure equal to the scale is
+// worth 0.5.
+func decay(measure, scale float64) float64 {
+	if measure <= 0 {
+		return 1
+	}
+
+	return scale / (scale + measure)
+}
+
+func scoreValue(t *entity.Trip) float64 {
+	if t.Match == nil || t.Match.Score == nil {
+		return 0
+	}
+
+	return t.Match.Score.Value
+}
@@ -0,0 +1,150 @@
+package trip
+
+import (
+	"testing"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+var rankingLeaveAt = time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+// newTestRankedTrip creates a matched trip whose pickup is the given walking
+// distance (in meters) away from the passenger, and leaves the given time
+// after the time the passenger wants to leave at.
+func newTestRankedTrip(ID entity.ID, walkingDistance float64, delay time.Duration) *entity.Trip {
+	return &entity.Trip{
+		ID:    ID,
+		Seats: 2,
+		Stops: []*entity.Stop{
+			{ID: "pickup", Seats: 2, DepartureTime: rankingLeaveAt.Add(delay)},
+			{ID: "dropOff"},
+		},
+		TotalTripPrice: 20,
+		Match: &entity.Match{
+			PickupID:       "pickup",
+			DropOffID:      "dropOff",
+			PickupDistance: walkingDistance,
+		},
+	}
+}
+
+func TestDecay(t *testing.T) {
+	tests := []struct {
+		measure float64
+		want    float64
+	}{
+		{-1, 1},
+		{0, 1},
+		{10, 0.5},
+		{30, 0.25},
+	}
+
+	for _, tt := range tests {
+		if got := decay(tt.measure, 10); got != tt.want {
+			t.Errorf("decay(%v, 10) = %v, want %v", tt.measure, got, tt.want)
+		}
+	}
+}
+
+func TestNewRankerNegativeWeight(t *testing.T) {
+	_, err := NewRanker(&RankingWeights{WalkingDistance: -1})
+	if err == nil {
+		t.Error("NewRanker() error = nil, want an error")
+	}
+}
+
+func TestRankerRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights RankingWeights
+		trips   []*entity.Trip
+		want    []entity.ID
+	}{
+		{
+			"walking distance outweighs time difference",
+			DefaultRankingWeights,
+			[]*entity.Trip{
+				newTestRankedTrip("far", 2000, 0),
+				newTestRankedTrip("late", 0, time.Hour),
+			},
+			[]entity.ID{"late", "far"},
+		},
+		{
+			"time difference outweighs walking distance",
+			RankingWeights{WalkingDistance: 1, TimeDifference: 10},
+			[]*entity.Trip{
+				newTestRankedTrip("late", 0, time.Hour),
+				newTestRankedTrip("far", 2000, 0),
+			},
+			[]entity.ID{"far", "late"},
+		},
+		{
+			"early and late departures are equally distant",
+			DefaultRankingWeights,
+			[]*entity.Trip{
+				newTestRankedTrip("late", 0, 2*time.Hour),
+				newTestRankedTrip("early", 0, -time.Hour),
+			},
+			[]entity.ID{"early", "late"},
+		},
+		{
+			"ties keep their order",
+			DefaultRankingWeights,
+			[]*entity.Trip{
+				newTestRankedTrip("first", 500, 0),
+				newTestRankedTrip("second", 500, 0),
+				newTestRankedTrip("best", 0, 0),
+				newTestRankedTrip("third", 500, 0),
+			},
+			[]entity.ID{"best", "first", "second", "third"},
+		},
+		{
+			"trips without a match come last",
+			DefaultRankingWeights,
+			[]*entity.Trip{
+				{ID: "unmatched"},
+				newTestRankedTrip("matched", 5000, 3*time.Hour),
+			},
+			[]entity.ID{"matched", "unmatched"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRanker(&tt.weights)
+			if err != nil {
+				t.Fatalf("NewRanker() error = %v", err)
+			}
+
+			r.Rank(tt.trips, &entity.Filters{LeaveAt: rankingLeaveAt})
+
+			for i, ID := range tt.want {
+				if tt.trips[i].ID != ID {
+					t.Fatalf("Rank() trip #%d = %s, want %s", i+1, tt.trips[i].ID, ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRankerScore(t *testing.T) {
+	r, err := NewRanker(&DefaultRankingWeights)
+	if err != nil {
+		t.Fatalf("NewRanker() error = %v", err)
+	}
+
+	trip := newTestRankedTrip("trip", WalkingDistanceScale, -TimeDifferenceScale)
+	trip.TotalTripPrice = PricePerSeatScale
+	r.Rank([]*entity.Trip{trip}, &entity.Filters{LeaveAt: rankingLeaveAt})
+
+	s := trip.Match.Score
+	if s.WalkingDistance.Value != 0.5 || s.TimeDifference.Value != 0.5 || s.PricePerSeat.Value != 0.5 || s.RemainingSeats.Value != 1 {
+		t.Errorf("Rank() score = %+v, want halved distance, time and price, and all seats remaining", s)
+	}
+
+	want := (0.5*4 + 0.5*3 + 0.5*2 + 1*1) / 10
+	if s.Value != want {
+		t.Errorf("Rank() score value = %v, want %v", s.Value, want)
+	}
+}
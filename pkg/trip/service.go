@@ -1,30 +1,63 @@
 package trip
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"azure.com/ecovo/trip-service/pkg/entity"
+	"azure.com/ecovo/trip-service/pkg/geocode"
+	"azure.com/ecovo/trip-service/pkg/pricing"
 	"azure.com/ecovo/trip-service/pkg/pubsub"
 	"azure.com/ecovo/trip-service/pkg/pubsub/subscription"
 	"azure.com/ecovo/trip-service/pkg/route"
+	"azure.com/ecovo/trip-service/pkg/search"
 )
 
 // UseCase is an interface representing the ability to handle the business
 // logic that involves trips.
 type UseCase interface {
-	Register(t *entity.Trip) (*entity.Trip, error)
+	Register(ctx context.Context, t *entity.Trip) (*entity.Trip, error)
+	Quote(ctx context.Context, t *entity.Trip) (*entity.Trip, error)
 	FindByID(ID entity.ID) (*entity.Trip, error)
 	Find(filters *entity.Filters) ([]*entity.Trip, error)
+	Facets(filters *entity.FacetFilters) (*entity.Facets, error)
+	Detour(ctx context.Context, ID entity.ID, r *entity.DetourRequest) (*entity.Detour, error)
+	QuoteFare(ID entity.ID, r *entity.FareQuoteRequest) (*entity.FareQuote, error)
+	Propose(ID entity.ID, p *entity.Proposal) (*entity.Proposal, error)
+	AcceptProposal(ctx context.Context, ID entity.ID, proposalID entity.ID) (*entity.Trip, error)
+	RejectProposal(ID entity.ID, proposalID entity.ID) (*entity.Proposal, error)
 	Update(t *entity.Trip) error
 	Delete(ID entity.ID) error
 }
 
 // A Service handles the business logic related to trips.
 type Service struct {
-	repo         Repository
-	subscription subscription.Subscription
-	routeService route.UseCase
+	repo           Repository
+	subscription   subscription.Subscription
+	pubSubService  pubsub.UseCase
+	reservations   ReservationRepository
+	geocodeService geocode.UseCase
+	routeService   route.UseCase
+	pricingService pricing.UseCase
+	searchService  search.UseCase
+	ranker         *Ranker
+}
+
+// A ReservationRepository gives access to the reservations made on trips, so
+// that their passengers can be notified when the trips change.
+type ReservationRepository interface {
+	FindByTripID(tripID entity.ID) ([]*entity.Reservation, error)
+}
+
+// A Reschedule is the notification sent to a passenger whose pickup or
+// drop-off time has changed.
+type Reschedule struct {
+	TripID        entity.ID `json:"tripId"`
+	ReservationID entity.ID `json:"reservationId"`
+	PickupTime    time.Time `json:"pickupTime"`
+	DropOffTime   time.Time `json:"dropOffTime"`
 }
 
 const (
@@ -34,27 +67,22 @@ const (
 
 // NewService creates a trip service to handle business logic and manipulate
 // trips through a repository.
-func NewService(repo Repository, pubSubService pubsub.UseCase, routeService route.UseCase) *Service {
+func NewService(repo Repository, pubSubService pubsub.UseCase, reservations ReservationRepository, geocodeService geocode.UseCase, routeService route.UseCase, pricingService pricing.UseCase, searchService search.UseCase, ranker *Ranker) *Service {
 	sub, err := pubSubService.Subscribe(topic)
 	if err != nil {
 		return nil
 	}
 
-	return &Service{repo, sub, routeService}
+	return &Service{repo, sub, pubSubService, reservations, geocodeService, routeService, pricingService, searchService, ranker}
 }
 
 // Register validates the trips's information
-func (s *Service) Register(t *entity.Trip) (*entity.Trip, error) {
+func (s *Service) Register(ctx context.Context, t *entity.Trip) (*entity.Trip, error) {
 	if t == nil {
 		return nil, fmt.Errorf("trip.Service: trip is nil")
 	}
 
-	err := t.Validate()
-	if err != nil {
-		return nil, err
-	}
-
-	err = s.routeService.CreateRoute(t)
+	err := s.plan(ctx, t)
 	if err != nil {
 		return nil, err
 	}
@@ -64,14 +92,70 @@ func (s *Service) Register(t *entity.Trip) (*entity.Trip, error) {
 		return nil, err
 	}
 
+	addedTrip := withoutProposals(t)
 	err = s.subscription.Publish(&subscription.Message{
 		Type: EventTripAdded,
-		Data: t,
+		Data: addedTrip,
 	})
+	if err != nil {
+		log.Println(err)
+	}
+
+	go func() {
+		err := s.searchService.NotifyTripAdded(addedTrip)
+		if err != nil {
+			log.Println(err)
+		}
+	}()
 
 	return t, nil
 }
 
+// Quote validates the trip's information and computes its route, times and
+// price like Register does, without saving it, so that the driver can preview
+// it before publishing it.
+func (s *Service) Quote(ctx context.Context, t *entity.Trip) (*entity.Trip, error) {
+	if t == nil {
+		return nil, fmt.Errorf("trip.Service: trip is nil")
+	}
+
+	err := s.plan(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// plan resolves the trip's stops, validates it and computes its route, times,
+// price, split between the seats already reserved, and emissions.
+func (s *Service) plan(ctx context.Context, t *entity.Trip) error {
+	err := s.geocodeService.ResolveStops(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	err = t.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = s.routeService.CreateRoute(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	err = s.pricingService.PriceTrip(t)
+	if err != nil {
+		return err
+	}
+
+	t.UpdateReservationCount(0)
+	t.EstimateEmissions()
+
+	return nil
+}
+
 // FindByID retrieves the trip with the given ID in the repository, if it
 // exists.
 func (s *Service) FindByID(ID entity.ID) (*entity.Trip, error) {
@@ -83,19 +167,259 @@ func (s *Service) FindByID(ID entity.ID) (*entity.Trip, error) {
 	return t, nil
 }
 
-// Find retrieves all the trips
+// Find retrieves all the trips that match the filters, ranked from most to
+// least relevant. When the filters do not specify a location, the trips that
+// satisfy the other criteria and have seats left are kept even if they have
+// no match.
 func (s *Service) Find(filters *entity.Filters) ([]*entity.Trip, error) {
 	err := filters.Validate()
 	if err != nil {
 		return nil, err
 	}
 
-	t, err := s.repo.Find(filters)
+	candidates, err := s.repo.Find(filters)
 	if err != nil {
 		return []*entity.Trip{}, err
 	}
 
-	return t, nil
+	trips := make([]*entity.Trip, 0, len(candidates))
+	for _, t := range candidates {
+		t.Match = filters.Match(t)
+		if t.Match != nil || (!filters.HasLocation() && filters.Accepts(t) && filters.HasSeats(t)) {
+			trips = append(trips, t)
+		}
+	}
+
+	s.ranker.Rank(trips, filters)
+
+	return trips, nil
+}
+
+// Detour estimates the extra distance and time it takes the driver of the trip
+// with the given ID to pick up and drop off a passenger off the route.
+func (s *Service) Detour(ctx context.Context, ID entity.ID, r *entity.DetourRequest) (*entity.Detour, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.routeService.EstimateDetour(ctx, t, r.Source(), r.Destination())
+}
+
+// QuoteFare computes the fare a passenger would pay to reserve seats on a
+// segment of the trip with the given ID.
+func (s *Service) QuoteFare(ID entity.ID, r *entity.FareQuoteRequest) (*entity.FareQuote, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.QuoteFare(entity.NewIDFromHex(r.SourceID), entity.NewIDFromHex(r.DestinationID), *r.Seats)
+}
+
+// Propose adds a stop proposed by a passenger to the trip with the given ID,
+// pending the driver's answer, and notifies the driver.
+func (s *Service) Propose(ID entity.ID, p *entity.Proposal) (*entity.Proposal, error) {
+	if p == nil {
+		return nil, fmt.Errorf("trip.Service: proposal is nil")
+	}
+
+	err := p.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ID = ""
+	p.Status = entity.ProposalStatusPending
+	p.StopID = ""
+	t.Proposals = append(t.Proposals, p)
+
+	err = s.Update(t)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(t.DriverID, EventProposalAdded, p)
+
+	return p, nil
+}
+
+// AcceptProposal inserts the stop proposed by a passenger in the trip with the
+// given ID, at the position that lengthens its route the least according to
+// the routing provider, and updates the trip's route, times and price. The
+// passenger who proposed it is notified, as well as the passengers whose
+// pickup or drop-off time changed.
+func (s *Service) AcceptProposal(ctx context.Context, ID entity.ID, proposalID entity.ID) (*entity.Trip, error) {
+	t, err := s.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := t.Proposal(proposalID)
+	if p == nil {
+		return nil, NotFoundError{fmt.Sprintf("trip.Service: no proposal found with ID \"%s\"", proposalID)}
+	}
+
+	err = p.Answer(true)
+	if err != nil {
+		return nil, err
+	}
+
+	detour, err := s.routeService.EstimateDetour(ctx, t, p.Point, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	departures := make(map[entity.ID]time.Time, len(t.Stops))
+	arrivals := make(map[entity.ID]time.Time, len(t.Stops))
+	for _, stop := range t.Stops {
+		departures[stop.ID] = stop.Departure()
+		arrivals[stop.ID] = stop.Arrival()
+	}
+
+	stop := &entity.Stop{ID: entity.NewID(), Point: p.Point}
+	p.StopID = stop.ID
+
+	// The stops are not reordered, since reservations refer to them. The
+	// departure time is kept and the other times follow from the new route,
+	// for which there are no alternatives.
+	accepted := t.WithInsertedStops(stop, nil, &entity.Insertion{PickupIndex: detour.PickupIndex})
+	accepted.ArriveBy = time.Time{}
+	accepted.RouteIndex = 0
+	accepted.RouteSummary = ""
+	err = s.routeService.CreateRoute(ctx, accepted)
+	if err != nil {
+		return nil, err
+	}
+	accepted.OptimizeStops = t.OptimizeStops
+
+	err = s.pricingService.PriceTrip(accepted)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted.UpdateReservationCount(0)
+	accepted.EstimateEmissions()
+
+	err = s.Update(accepted)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(p.UserID, EventProposalAccepted, p)
+	s.notifyRescheduled(accepted, departures, arrivals)
+
+	return accepted, nil
+}
+
+// RejectProposal rejects the stop proposed by a passenger for the trip with
+// the given ID and notifies the passenger.
+func (s *Service) RejectProposal(ID entity.ID, proposalID entity.ID) (*entity.Proposal, error) {
+	t, err := s.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := t.Proposal(proposalID)
+	if p == nil {
+		return nil, NotFoundError{fmt.Sprintf("trip.Service: no proposal found with ID \"%s\"", proposalID)}
+	}
+
+	err = p.Answer(false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Update(t)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(p.UserID, EventProposalRejected, p)
+
+	return p, nil
+}
+
+// notifyRescheduled notifies the passengers of the trip whose pickup or
+// drop-off time differs from the given departure and arrival times of the
+// stops.
+func (s *Service) notifyRescheduled(t *entity.Trip, departures, arrivals map[entity.ID]time.Time) {
+	reservations, err := s.reservations.FindByTripID(t.ID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	stops := make(map[entity.ID]*entity.Stop, len(t.Stops))
+	for _, stop := range t.Stops {
+		stops[stop.ID] = stop
+	}
+
+	for _, r := range reservations {
+		pickup, dropOff := stops[r.SourceID], stops[r.DestinationID]
+		if pickup == nil || dropOff == nil {
+			continue
+		}
+
+		if pickup.Departure().Equal(departures[r.SourceID]) && dropOff.Arrival().Equal(arrivals[r.DestinationID]) {
+			continue
+		}
+
+		s.notify(r.UserID, EventTripRescheduled, &Reschedule{t.ID, r.ID, pickup.Departure(), dropOff.Arrival()})
+	}
+}
+
+// notify publishes a message to the user with the given ID. Failing to notify
+// the user is only logged.
+func (s *Service) notify(userID entity.ID, eventType string, data interface{}) {
+	err := s.pubSubService.Publish(pubsub.UserTopic(userID.Hex()), &subscription.Message{
+		Type: eventType,
+		Data: data,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Facets counts the trips that match the filters, broken down by luggage
+// size, animals, departure hour and price. The trips are found like Find does,
+// so that the counts agree with the search results.
+func (s *Service) Facets(filters *entity.FacetFilters) (*entity.Facets, error) {
+	err := filters.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if filters.Timezone == "" {
+		filters.Timezone = entity.DefaultTimezone
+	}
+
+	loc, err := time.LoadLocation(filters.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("trip.Service: failed to load time zone (%s)", err)
+	}
+
+	trips, err := s.Find(&filters.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewFacets(trips, loc), nil
 }
 
 // Update validates that the trip contains all the required personal
@@ -125,7 +449,7 @@ func (s *Service) Update(modifiedTrip *entity.Trip) error {
 
 	err = s.subscription.Publish(&subscription.Message{
 		Type: EventTripChanged,
-		Data: t,
+		Data: withoutProposals(t),
 	})
 
 	if err != nil {
@@ -135,6 +459,16 @@ func (s *Service) Update(modifiedTrip *entity.Trip) error {
 	return nil
 }
 
+// withoutProposals returns a copy of the trip without its proposals, to be
+// published to users who are not allowed to see them. The driver is notified
+// of the proposals on their own topic instead.
+func withoutProposals(t *entity.Trip) *entity.Trip {
+	published := *t
+	published.Proposals = nil
+
+	return &published
+}
+
 // Delete erases the trip from the repository.
 func (s *Service) Delete(ID entity.ID) error {
 	err := s.repo.Delete(ID)
@@ -0,0 +1,41 @@
+package usage
+
+import (
+	"context"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+type contextKey string
+
+func (c contextKey) String() string {
+	return string(c)
+}
+
+const (
+	// UserIDContextKey represents the key used to store and retrieve the ID
+	// of the user to whom the calls made during a request are charged.
+	UserIDContextKey = contextKey("usage-user-id")
+)
+
+// NewContext returns a copy of the given context in which the calls made to
+// the routing providers are charged to the user with the given ID.
+func NewContext(ctx context.Context, userID entity.ID) context.Context {
+	return context.WithValue(ctx, UserIDContextKey, userID)
+}
+
+// UserIDFromContext extracts the ID of the user to whom the calls are charged
+// from a context. The nil ID is returned when the context has none, such as
+// for requests made by other services.
+func UserIDFromContext(ctx context.Context) entity.ID {
+	if ctx == nil {
+		return entity.NilID
+	}
+
+	userID, ok := ctx.Value(UserIDContextKey).(entity.ID)
+	if !ok {
+		return entity.NilID
+	}
+
+	return userID
+}
@@ -0,0 +1,129 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/options"
+)
+
+// A MongoRepository is a repository that records the calls made to routing
+// providers in a MongoDB collection.
+type MongoRepository struct {
+	collection *mongo.Collection
+}
+
+type document struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Provider  string             `bson:"provider"`
+	UserID    string             `bson:"userId"`
+	RequestID string             `bson:"requestId"`
+	Elements  int                `bson:"elements"`
+	Latency   int64              `bson:"latency"`
+	Outcome   string             `bson:"outcome"`
+	Timestamp time.Time          `bson:"timestamp"`
+}
+
+func newDocumentFromEntity(u *entity.RouteUsage) (*document, error) {
+	if u == nil {
+		return nil, fmt.Errorf("usage.MongoRepository: entity is nil")
+	}
+
+	return &document{
+		primitive.NilObjectID,
+		u.Provider,
+		string(u.UserID),
+		u.RequestID,
+		u.Elements,
+		u.Latency,
+		u.Outcome,
+		u.Timestamp,
+	}, nil
+}
+
+// NewMongoRepository creates a usage repository for a MongoDB collection.
+func NewMongoRepository(collection *mongo.Collection) (Repository, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("usage.MongoRepository: collection is nil")
+	}
+
+	err := createIndexes(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoRepository{collection}, nil
+}
+
+// Creates the indexes required to count the calls of a provider or a user
+// since a given time, if they do not exist, and the index that expires the
+// calls once they are past the retention period.
+func createIndexes(collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{"provider", 1}, {"timestamp", 1}},
+		},
+		{
+			Keys: bson.D{{"userId", 1}, {"timestamp", 1}},
+		},
+		{
+			Keys: bson.D{{"timestamp", 1}},
+			Options: options.Index().
+				SetExpireAfterSeconds(int32(Retention / time.Second)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("usage.MongoRepository: failed to create indexes (%s)", err)
+	}
+
+	return nil
+}
+
+// Create stores the call made to a routing provider in the database.
+func (r *MongoRepository) Create(u *entity.RouteUsage) error {
+	d, err := newDocumentFromEntity(u)
+	if err != nil {
+		return fmt.Errorf("usage.MongoRepository: failed to create usage document from entity (%s)", err)
+	}
+
+	_, err = r.collection.InsertOne(context.TODO(), d)
+	if err != nil {
+		return fmt.Errorf("usage.MongoRepository: failed to create usage (%s)", err)
+	}
+
+	return nil
+}
+
+// Count counts the calls made since the given time to the routing provider
+// with the given name that are charged to the user with the given ID and
+// have the given outcome. An empty provider, user ID or outcome counts the
+// calls of all providers, users or outcomes.
+func (r *MongoRepository) Count(provider string, userID entity.ID, outcome string, since time.Time) (int, error) {
+	filter := bson.D{}
+
+	if provider != "" {
+		filter = append(filter, bson.E{"provider", provider})
+	}
+
+	if !userID.IsZero() {
+		filter = append(filter, bson.E{"userId", string(userID)})
+	}
+
+	filter = append(filter, bson.E{"timestamp", bson.M{"$gte": since}})
+
+	if outcome != "" {
+		filter = append(filter, bson.E{"outcome", outcome})
+	}
+
+	count, err := r.collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, fmt.Errorf("usage.MongoRepository: failed to count usage (%s)", err)
+	}
+
+	return int(count), nil
+}
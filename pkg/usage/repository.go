@@ -0,0 +1,18 @@
+package usage
+
+import (
+	"time"
+
+	"azure.com/ecovo/trip-service/pkg/entity"
+)
+
+// Repository is an interface representing the ability to record and count
+// the calls made to routing providers in a database.
+type Repository interface {
+	Create(u *entity.RouteUsage) error
+	Count(provider string, userID entity.ID, outcome string, since time.Time) (int, error)
+}
+
+// Retention represents how long the calls made to routing providers are kept
+// in the database before they expire.
+const Retention = 90 * 24 * time.Hour